@@ -0,0 +1,205 @@
+// Package backtest replays historical candles through a trading strategy. The engine is long only: a buy signal
+// invests all of the cash and a sell signal closes the whole position. Orders fill at the open of the candle after the
+// signal so a strategy never trades on a price it could not have seen.
+package backtest
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// Candle is a single OHLCV bar.
+type Candle struct {
+	Time   time.Time
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	Volume float64
+}
+
+// Signal is the action a strategy wants to take.
+type Signal int
+
+// Signals returned by a strategy.
+const (
+	Hold Signal = iota
+	Buy
+	Sell
+)
+
+// Strategy decides what to do after each candle closes.
+type Strategy interface {
+	// Name describes the strategy and its parameters.
+	Name() string
+
+	// Next is called with every candle up to and including the one that just closed.
+	Next(history []Candle) Signal
+}
+
+// Config configures a backtest.
+type Config struct {
+	// Capital is the starting cash.
+	Capital float64
+
+	// Fee is the commission rate charged on each fill.
+	Fee float64
+
+	// PeriodsPerYear is the number of candles in a year used to annualize the Sharpe ratio.
+	PeriodsPerYear float64
+}
+
+// Trade is a completed round trip.
+type Trade struct {
+	EntryTime  time.Time
+	EntryPrice float64
+	ExitTime   time.Time
+	ExitPrice  float64
+	Quantity   float64
+	Fees       float64
+	Profit     float64
+	Return     float64
+}
+
+// EquityPoint is the value of the account at the close of a candle.
+type EquityPoint struct {
+	Time  time.Time
+	Value float64
+}
+
+// Result is the outcome of a backtest.
+type Result struct {
+	Trades      []Trade
+	Equity      []EquityPoint
+	Capital     float64
+	FinalEquity float64
+	TotalReturn float64
+	MaxDrawdown float64
+	WinRate     float64
+	Sharpe      float64
+	Fees        float64
+}
+
+// Run replays the candles through the strategy. A position still open after the last candle is closed at its close.
+func Run(candles []Candle, strategy Strategy, config Config) (*Result, error) {
+	if len(candles) == 0 {
+		return nil, errors.New("no candles to backtest")
+	}
+	if config.Capital <= 0 {
+		return nil, errors.New("capital must be greater than 0")
+	}
+	if config.Fee < 0 || config.Fee >= 1 {
+		return nil, errors.New("fee must be between 0 and 1")
+	}
+
+	result := &Result{Capital: config.Capital}
+	cash := config.Capital
+	var quantity float64
+	var open Trade
+
+	buy := func(candle Candle) {
+		fee := cash * config.Fee
+		open = Trade{
+			EntryTime:  candle.Time,
+			EntryPrice: candle.Open,
+			Quantity:   (cash - fee) / candle.Open,
+			Fees:       fee,
+		}
+		quantity = open.Quantity
+		cash = 0
+	}
+	sell := func(at time.Time, price float64) {
+		proceeds := quantity * price
+		fee := proceeds * config.Fee
+		cost := open.Quantity*open.EntryPrice + open.Fees
+
+		open.ExitTime = at
+		open.ExitPrice = price
+		open.Fees += fee
+		open.Profit = proceeds - fee - cost
+		open.Return = open.Profit / cost
+		result.Trades = append(result.Trades, open)
+
+		cash = proceeds - fee
+		quantity = 0
+	}
+
+	signal := Hold
+	for index, candle := range candles {
+		// orders from the previous signal fill at this open
+		if signal == Buy && quantity == 0 {
+			buy(candle)
+		} else if signal == Sell && quantity > 0 {
+			sell(candle.Time, candle.Open)
+		}
+
+		result.Equity = append(result.Equity, EquityPoint{Time: candle.Time, Value: cash + quantity*candle.Close})
+		signal = strategy.Next(candles[:index+1])
+	}
+
+	last := candles[len(candles)-1]
+	if quantity > 0 {
+		sell(last.Time, last.Close)
+		result.Equity[len(result.Equity)-1].Value = cash
+	}
+
+	result.FinalEquity = cash
+	result.TotalReturn = (cash - config.Capital) / config.Capital
+	result.MaxDrawdown = maxDrawdown(result.Equity)
+	result.Sharpe = sharpe(result.Equity, config.PeriodsPerYear)
+
+	var wins int
+	for _, trade := range result.Trades {
+		result.Fees += trade.Fees
+		if trade.Profit > 0 {
+			wins++
+		}
+	}
+	if len(result.Trades) > 0 {
+		result.WinRate = float64(wins) / float64(len(result.Trades))
+	}
+	return result, nil
+}
+
+// maxDrawdown is the largest fall from a peak in the equity curve as a fraction of the peak.
+func maxDrawdown(equity []EquityPoint) float64 {
+	var peak, drawdown float64
+	for _, point := range equity {
+		peak = math.Max(peak, point.Value)
+		if peak > 0 {
+			drawdown = math.Max(drawdown, (peak-point.Value)/peak)
+		}
+	}
+	return drawdown
+}
+
+// sharpe is the annualized Sharpe ratio of the returns between candles with a risk free rate of 0.
+func sharpe(equity []EquityPoint, periodsPerYear float64) float64 {
+	if len(equity) < 3 || periodsPerYear <= 0 {
+		return 0
+	}
+
+	returns := make([]float64, 0, len(equity)-1)
+	for index := 1; index < len(equity); index++ {
+		if equity[index-1].Value > 0 {
+			returns = append(returns, equity[index].Value/equity[index-1].Value-1)
+		}
+	}
+
+	var mean float64
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	var variance float64
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	deviation := math.Sqrt(variance / float64(len(returns)-1))
+	if deviation == 0 {
+		return 0
+	}
+	return mean / deviation * math.Sqrt(periodsPerYear)
+}
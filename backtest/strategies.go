@@ -0,0 +1,92 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/eliquious/mercator/indicators"
+)
+
+// closes returns the closing prices of the latest candles.
+func closes(history []Candle, length int) []float64 {
+	if len(history) > length {
+		history = history[len(history)-length:]
+	}
+
+	values := make([]float64, len(history))
+	for index, candle := range history {
+		values[index] = candle.Close
+	}
+	return values
+}
+
+// SMACross buys when the fast moving average crosses above the slow one and sells when it crosses below.
+type SMACross struct {
+	Fast int
+	Slow int
+}
+
+// Name describes the strategy.
+func (s SMACross) Name() string {
+	return fmt.Sprintf("SMA cross (%d/%d)", s.Fast, s.Slow)
+}
+
+// Next compares the moving averages of the last two candles.
+func (s SMACross) Next(history []Candle) Signal {
+	values := closes(history, s.Slow+1)
+	fast := indicators.SMA(values, s.Fast)
+	slow := indicators.SMA(values, s.Slow)
+	if len(values) < s.Slow+1 {
+		return Hold
+	}
+
+	last := len(values) - 1
+	if fast[last-1] <= slow[last-1] && fast[last] > slow[last] {
+		return Buy
+	} else if fast[last-1] >= slow[last-1] && fast[last] < slow[last] {
+		return Sell
+	}
+	return Hold
+}
+
+// rsiWarmup is the number of periods of history used so the smoothed RSI settles.
+const rsiWarmup = 10
+
+// RSIReversion buys when the RSI is oversold and sells when it is overbought.
+type RSIReversion struct {
+	Period     int
+	Oversold   float64
+	Overbought float64
+}
+
+// Name describes the strategy.
+func (s RSIReversion) Name() string {
+	return fmt.Sprintf("RSI reversion (%d, %0.f/%0.f)", s.Period, s.Oversold, s.Overbought)
+}
+
+// Next checks the RSI of the last candle.
+func (s RSIReversion) Next(history []Candle) Signal {
+	rsi := indicators.Last(indicators.RSI(closes(history, s.Period*rsiWarmup), s.Period))
+	switch {
+	case math.IsNaN(rsi):
+		return Hold
+	case rsi <= s.Oversold:
+		return Buy
+	case rsi >= s.Overbought:
+		return Sell
+	}
+	return Hold
+}
+
+// BuyAndHold buys on the first candle and holds to the end. It is the benchmark for other strategies.
+type BuyAndHold struct{}
+
+// Name describes the strategy.
+func (s BuyAndHold) Name() string {
+	return "Buy and hold"
+}
+
+// Next always buys. The engine ignores buys while a position is open.
+func (s BuyAndHold) Next(history []Candle) Signal {
+	return Buy
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/eliquious/console"
+	"github.com/eliquious/mercator/binance"
+	"github.com/gookit/color"
+	"github.com/kballard/go-shellquote"
+)
+
+// runCommand runs a single command given on the command line and returns the exit status.
+func runCommand(env *console.Environment, args []string) int {
+	if err := execute(env, args); err != nil {
+		printError(err)
+		return 1
+	}
+	return 0
+}
+
+// runScript runs the commands in a script file, or stdin for "-", in order and returns the exit status. Each line is
+// a command as it would be typed in the console. Blank lines and lines starting with # are skipped. The script stops
+// at the first command that fails, including confirmations that are declined or cannot be asked.
+func runScript(env *console.Environment, path string) int {
+	// commands cannot ask for confirmation on stdin while it holds the script
+	file := os.Stdin
+	if path == "-" {
+		env.Configuration.Set(binance.NoPromptKey, true)
+	} else {
+		var err error
+		if file, err = os.Open(path); err != nil {
+			printError(err)
+			return 1
+		}
+		defer file.Close()
+	}
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		args, err := shellquote.Split(text)
+		if err == nil {
+			err = execute(env, args)
+		}
+		if err != nil {
+			printError(fmt.Errorf("%s:%d: %s", path, line, err))
+			return 1
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		printError(err)
+		return 1
+	}
+	return 0
+}
+
+// execute runs a command in the current scope. A leading scope name runs the rest of the command in that scope, so
+// `binance symbol-price BTCUSDT` works from the root scope. A scope name on its own enters the scope like `use`.
+func execute(env *console.Environment, args []string) error {
+	if len(args) == 0 {
+		return errors.New("no command given")
+	}
+
+	scope := env.CurrentScope()
+	sub, ok := scope.SubScopes()[args[0]]
+	if !ok {
+		if _, ok := scope.Commands()[args[0]]; !ok {
+			return fmt.Errorf("unknown command: %s", args[0])
+		}
+		return scope.Execute(env, args)
+	}
+
+	env.Push(sub)
+	if len(args) == 1 {
+		return nil
+	}
+	defer env.Pop()
+	return execute(env, args[1:])
+}
+
+// printError prints an error to stderr so it does not mix with the output of the commands.
+func printError(err error) {
+	fmt.Fprintln(os.Stderr, color.Error.Sprint(err))
+}
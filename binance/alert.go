@@ -0,0 +1,445 @@
+package binance
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	binance "github.com/adshao/go-binance/v2"
+	"github.com/eliquious/console"
+	"github.com/gookit/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/viper"
+)
+
+// alertHookTimeout limits how long a webhook or shell hook may run.
+const alertHookTimeout = 10 * time.Second
+
+// defaultAlertInterval is how often the alerts are checked unless binance.alert_interval is set.
+const defaultAlertInterval = 10 * time.Second
+
+// priceAlert fires once when the last price of a symbol crosses the alert price.
+type priceAlert struct {
+	ID        int     `json:"id"`
+	Symbol    string  `json:"symbol"`
+	Condition string  `json:"condition"`
+	Price     float64 `json:"price"`
+	Webhook   string  `json:"webhook,omitempty"`
+	Command   string  `json:"command,omitempty"`
+}
+
+// triggered returns true if the price meets the alert condition.
+func (a priceAlert) triggered(price float64) bool {
+	if a.Condition == "above" {
+		return price >= a.Price
+	}
+	return price <= a.Price
+}
+
+func (a priceAlert) String() string {
+	return fmt.Sprintf("#%d %s %s %s", a.ID, a.Symbol, a.Condition, strconv.FormatFloat(a.Price, 'f', -1, 64))
+}
+
+// getAlertsFile returns the file the price alerts are saved in.
+func getAlertsFile(conf *viper.Viper) (string, error) {
+	path := conf.GetString("binance.alerts_file")
+	if path == "" {
+		return "", errors.New("binance.alerts_file is not set")
+	}
+	return path, nil
+}
+
+// loadAlerts reads the price alerts from the alerts file. A missing file has no alerts.
+func loadAlerts(path string) ([]priceAlert, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var alerts []priceAlert
+	if err := json.Unmarshal(data, &alerts); err != nil {
+		return nil, fmt.Errorf("invalid alerts file %s: %s", path, err)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].ID < alerts[j].ID
+	})
+	return alerts, nil
+}
+
+// saveAlerts writes the price alerts to the alerts file. The file is only readable by the user since the alerts can
+// run shell commands.
+func saveAlerts(path string, alerts []priceAlert) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	if alerts == nil {
+		alerts = []priceAlert{}
+	}
+	data, err := json.MarshalIndent(alerts, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// alertMonitor polls the prices in the background and fires the alerts in the alerts file. Fired alerts are
+// removed. The monitor only keeps the file and interval so the background checks never touch the configuration.
+type alertMonitor struct {
+	client *binance.Client
+
+	mu   sync.Mutex
+	path string
+	stop chan struct{}
+}
+
+func newAlertMonitor(client *binance.Client) *alertMonitor {
+	return &alertMonitor{client: client}
+}
+
+// start runs the monitor on the alerts file until no alerts are left. A running monitor switches to the file.
+func (m *alertMonitor) start(path string, interval time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.path = path
+	if m.stop != nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	m.stop = stop
+	if interval <= 0 {
+		interval = defaultAlertInterval
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := m.check(); err != nil {
+					color.Warn.Printf("\nalerts: %s\n", err)
+				}
+			}
+		}
+	}()
+}
+
+// update changes the alerts in the alerts file. Changes are serialized with the checks so fired alerts are not
+// brought back.
+func (m *alertMonitor) update(path string, change func([]priceAlert) ([]priceAlert, error)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	alerts, err := loadAlerts(path)
+	if err != nil {
+		return err
+	}
+	if alerts, err = change(alerts); err != nil {
+		return err
+	}
+	return saveAlerts(path, alerts)
+}
+
+func (m *alertMonitor) running() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stop != nil
+}
+
+// check fires the alerts whose condition is met by the latest prices. The monitor stops once no alerts are left.
+func (m *alertMonitor) check() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	alerts, err := loadAlerts(m.path)
+	if err != nil {
+		return err
+	}
+	if len(alerts) == 0 {
+		if m.stop != nil {
+			close(m.stop)
+			m.stop = nil
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), alertHookTimeout)
+	defer cancel()
+	resp, err := m.client.NewListPricesService().Do(ctx)
+	if err != nil {
+		return err
+	}
+	prices := make(map[string]float64, len(resp))
+	for _, price := range resp {
+		if value, err := strconv.ParseFloat(price.Price, 64); err == nil {
+			prices[price.Symbol] = value
+		}
+	}
+
+	var pending []priceAlert
+	for _, alert := range alerts {
+		price, ok := prices[alert.Symbol]
+		if !ok || !alert.triggered(price) {
+			pending = append(pending, alert)
+			continue
+		}
+		go fireAlert(alert, price)
+	}
+
+	if len(pending) == len(alerts) {
+		return nil
+	}
+	return saveAlerts(m.path, pending)
+}
+
+// fireAlert shows a notification in the terminal and runs the hooks of the alert.
+func fireAlert(alert priceAlert, price float64) {
+	last := strconv.FormatFloat(price, 'f', -1, 64)
+	fmt.Printf("\a\n%s %s (last %s)\n", color.LightYellow.Render("[alert]"), alert, last)
+
+	if alert.Webhook != "" {
+		if err := postAlertWebhook(alert, price); err != nil {
+			color.Warn.Printf("alert #%d webhook failed: %s\n", alert.ID, err)
+		}
+	}
+
+	if alert.Command != "" {
+		if err := runAlertCommand(alert, last); err != nil {
+			color.Warn.Printf("alert #%d command failed: %s\n", alert.ID, err)
+		}
+	}
+}
+
+// postAlertWebhook posts the alert and the last price as JSON.
+func postAlertWebhook(alert priceAlert, price float64) error {
+	// the hooks are left out of the body
+	body, err := json.Marshal(struct {
+		ID        int       `json:"id"`
+		Symbol    string    `json:"symbol"`
+		Condition string    `json:"condition"`
+		Price     float64   `json:"price"`
+		Last      float64   `json:"last"`
+		Time      time.Time `json:"time"`
+	}{alert.ID, alert.Symbol, alert.Condition, alert.Price, price, time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: alertHookTimeout}
+	resp, err := client.Post(alert.Webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return nil
+}
+
+// runAlertCommand runs the shell hook of the alert. The alert is passed in MERCATOR_ALERT_* env variables.
+func runAlertCommand(alert priceAlert, last string) error {
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), alertHookTimeout)
+	defer cancel()
+
+	command := exec.CommandContext(ctx, shell, flag, alert.Command)
+	command.Env = append(os.Environ(),
+		"MERCATOR_ALERT_ID="+strconv.Itoa(alert.ID),
+		"MERCATOR_ALERT_SYMBOL="+alert.Symbol,
+		"MERCATOR_ALERT_CONDITION="+alert.Condition,
+		"MERCATOR_ALERT_PRICE="+strconv.FormatFloat(alert.Price, 'f', -1, 64),
+		"MERCATOR_ALERT_LAST="+last,
+	)
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	return command.Run()
+}
+
+func addAlertCommand(scope *console.Scope, monitor *alertMonitor, symbols []binance.Symbol) {
+	command := &console.Command{
+		Use:   "alert",
+		Short: "Add, list or remove price alerts (add|list|rm)",
+		Long: `Price alerts are saved in binance.alerts_file and checked in the background every binance.alert_interval
+(10s by default) while the console is running. When the last price crosses the alert price the console shows a
+notification, posts the alert as JSON to the webhook and runs the shell command with the alert in the
+MERCATOR_ALERT_ID, MERCATOR_ALERT_SYMBOL, MERCATOR_ALERT_CONDITION, MERCATOR_ALERT_PRICE and MERCATOR_ALERT_LAST
+env variables. An alert fires once and is then removed.
+
+    alert add BTCUSDT above 70000 --webhook https://example.com/hook
+    alert add ETHUSDT below 1800 --exec 'notify-send "$MERCATOR_ALERT_SYMBOL $MERCATOR_ALERT_LAST"'
+    alert list
+    alert rm 2
+		`,
+		ValidateArgs:     console.MinimumArgs(1),
+		EagerSuggestions: true,
+		Suggestions: func(env *console.Environment, args []string) []string {
+			// args starts with the command name
+			switch {
+			case len(args) == 2 && args[1] == "add":
+				return getSymbolList(symbols)
+			case len(args) == 2 && args[1] == "rm":
+				return getAlertIDs(env)
+			case len(args) == 3 && args[1] == "add":
+				if _, ok := getSymbolMap(symbols)[strings.ToUpper(args[2])]; ok {
+					return []string{"above", "below"}
+				}
+				return getSymbolList(symbols)
+			case len(args) == 4 && args[1] == "add":
+				return []string{"above", "below"}
+			case len(args) <= 2:
+				return []string{"add", "list", "rm"}
+			}
+			return []string{}
+		},
+		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
+			path, err := getAlertsFile(env.Configuration)
+			if err != nil {
+				return err
+			}
+			alerts, err := loadAlerts(path)
+			if err != nil {
+				return err
+			}
+
+			switch args[0] {
+			case "add":
+				if len(args) != 4 {
+					return errors.New("expected: alert add SYMBOL above|below PRICE")
+				}
+				symbol := strings.ToUpper(args[1])
+				if _, ok := getSymbolMap(symbols)[symbol]; !ok {
+					return errors.New("unknown symbol: " + args[1])
+				}
+				condition := strings.ToLower(args[2])
+				if condition != "above" && condition != "below" {
+					return errors.New("expected above or below: " + args[2])
+				}
+				price, err := strconv.ParseFloat(args[3], 64)
+				if err != nil || price <= 0 {
+					return errors.New("invalid price: " + args[3])
+				}
+
+				// the hooks are only taken from this run since console keeps flag values between runs
+				alert := priceAlert{ID: 1, Symbol: symbol, Condition: condition, Price: price}
+				if cmd.Flags().Changed("webhook") {
+					alert.Webhook, _ = cmd.Flags().GetString("webhook")
+				}
+				if cmd.Flags().Changed("exec") {
+					alert.Command, _ = cmd.Flags().GetString("exec")
+				}
+
+				err = monitor.update(path, func(alerts []priceAlert) ([]priceAlert, error) {
+					for _, existing := range alerts {
+						if existing.ID >= alert.ID {
+							alert.ID = existing.ID + 1
+						}
+					}
+					return append(alerts, alert), nil
+				})
+				if err != nil {
+					return err
+				}
+				monitor.start(path, env.Configuration.GetDuration("binance.alert_interval"))
+				fmt.Printf("Added alert %s\n", alert)
+			case "list":
+				if len(alerts) == 0 {
+					fmt.Println("No alerts")
+					return nil
+				}
+
+				table := tablewriter.NewWriter(os.Stdout)
+				table.SetHeader([]string{"ID", "Symbol", "Condition", "Price", "Webhook", "Command"})
+				for _, alert := range alerts {
+					table.Append([]string{
+						strconv.Itoa(alert.ID),
+						alert.Symbol,
+						alert.Condition,
+						strconv.FormatFloat(alert.Price, 'f', -1, 64),
+						alert.Webhook,
+						alert.Command,
+					})
+				}
+				table.Render() // Send output
+
+				status := "stopped"
+				if monitor.running() {
+					status = "running"
+				}
+				fmt.Printf("%s: %s\n", color.LightGreen.Render("Monitor"), status)
+			case "rm":
+				if len(args) != 2 {
+					return errors.New("expected: alert rm ID")
+				}
+				id, err := strconv.Atoi(strings.TrimPrefix(args[1], "#"))
+				if err != nil {
+					return errors.New("invalid alert id: " + args[1])
+				}
+
+				err = monitor.update(path, func(alerts []priceAlert) ([]priceAlert, error) {
+					var remaining []priceAlert
+					for _, alert := range alerts {
+						if alert.ID != id {
+							remaining = append(remaining, alert)
+						}
+					}
+					if len(remaining) == len(alerts) {
+						return nil, fmt.Errorf("no alert #%d", id)
+					}
+					return remaining, nil
+				})
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Removed alert #%d\n", id)
+			default:
+				return errors.New("expected add, list or rm")
+			}
+			return nil
+		},
+	}
+	command.Flags().String("webhook", "", "URL to post the alert to when it fires")
+	command.Flags().String("exec", "", "Shell command to run when the alert fires")
+	scope.AddCommand(command)
+}
+
+func getAlertIDs(env *console.Environment) []string {
+	path, err := getAlertsFile(env.Configuration)
+	if err != nil {
+		return []string{}
+	}
+	alerts, err := loadAlerts(path)
+	if err != nil {
+		return []string{}
+	}
+
+	ids := make([]string, 0, len(alerts))
+	for _, alert := range alerts {
+		ids = append(ids, strconv.Itoa(alert.ID))
+	}
+	return ids
+}
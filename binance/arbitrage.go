@@ -0,0 +1,190 @@
+package binance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	binance "github.com/adshao/go-binance/v2"
+	"github.com/eliquious/console"
+	"github.com/eliquious/mercator/output"
+)
+
+// bookQuote is the best bid and ask of a market.
+type bookQuote struct {
+	bid         float64
+	bidQuantity float64
+	ask         float64
+	askQuantity float64
+}
+
+// getBookQuotes returns the best bid and ask of every market. Markets without both sides of the book are skipped.
+func getBookQuotes(client *binance.Client) (map[string]bookQuote, error) {
+	tickers, err := client.NewListBookTickersService().Do(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	quotes := make(map[string]bookQuote, len(tickers))
+	for _, ticker := range tickers {
+		var quote bookQuote
+		values := []*float64{&quote.bid, &quote.bidQuantity, &quote.ask, &quote.askQuantity}
+		for index, text := range []string{ticker.BidPrice, ticker.BidQuantity, ticker.AskPrice, ticker.AskQuantity} {
+			if *values[index], err = strconv.ParseFloat(text, 64); err != nil {
+				return nil, fmt.Errorf("invalid book ticker: %s %s", ticker.Symbol, text)
+			}
+		}
+		if quote.bid > 0 && quote.ask > 0 {
+			quotes[ticker.Symbol] = quote
+		}
+	}
+	return quotes, nil
+}
+
+// stepRate returns the amount of the target asset received for one unit of the source asset after the fee, and the
+// most of the source asset the top of the book fills. Selling the base asset fills at the bid and buying it with the
+// quote asset fills at the ask.
+func stepRate(step conversionStep, quote bookQuote, fee float64) (float64, float64) {
+	if step.inverse {
+		return (1 - fee) / quote.ask, quote.askQuantity * quote.ask
+	}
+	return quote.bid * (1 - fee), quote.bidQuantity
+}
+
+// triangle is a cycle of three markets that starts and ends in the same asset.
+type triangle struct {
+	steps []conversionStep
+
+	// implied is the amount of the third asset received for one unit of the first through the second asset, and
+	// direct is the amount of the third asset the direct market needs to buy back one unit of the first.
+	implied float64
+	direct  float64
+
+	// gain is the return of the cycle and depth the most of the first asset the top of the books fill.
+	gain  float64
+	depth float64
+}
+
+// findTriangles evaluates every cycle of three markets. Each cycle is started from its first asset in sort order, or
+// from the start asset if one is given, and is evaluated in both directions.
+func findTriangles(graph *conversionGraph, quotes map[string]bookQuote, fee float64, start string) []triangle {
+	var triangles []triangle
+	for first, edges := range graph.edges {
+		if start != "" && first != start {
+			continue
+		}
+
+		for _, s1 := range edges {
+			for _, s2 := range graph.edges[s1.to] {
+				if s2.to == first || s2.symbol == s1.symbol {
+					continue
+				}
+				if start == "" && (s1.to < first || s2.to < first) {
+					continue
+				}
+
+				for _, s3 := range graph.edges[s2.to] {
+					if s3.to != first {
+						continue
+					}
+					if t, ok := evaluateTriangle([]conversionStep{s1, s2, s3}, quotes, fee); ok {
+						triangles = append(triangles, t)
+					}
+				}
+			}
+		}
+	}
+
+	sort.Slice(triangles, func(i, j int) bool {
+		return triangles[i].gain > triangles[j].gain
+	})
+	return triangles
+}
+
+func evaluateTriangle(steps []conversionStep, quotes map[string]bookQuote, fee float64) (triangle, bool) {
+	rates := make([]float64, len(steps))
+	depth := math.Inf(1)
+	cumulative := 1.0
+	for index, step := range steps {
+		quote, ok := quotes[step.symbol]
+		if !ok {
+			return triangle{}, false
+		}
+
+		rate, capacity := stepRate(step, quote, fee)
+		depth = math.Min(depth, capacity/cumulative)
+		cumulative *= rate
+		rates[index] = rate
+	}
+
+	return triangle{
+		steps:   steps,
+		implied: rates[0] * rates[1],
+		direct:  1 / rates[2],
+		gain:    cumulative - 1,
+		depth:   depth,
+	}, true
+}
+
+func addArbitrageScanCommand(scope *console.Scope, client *binance.Client, symbols []binance.Symbol) {
+	var start string
+	var fee, minimum float64
+	var limit int
+	command := &console.Command{
+		Use:   "arbitrage-scan",
+		Short: "Rank triangular arbitrage opportunities across all markets",
+		Long: `Builds a graph of every trading market and evaluates each triangle of markets that starts and ends in the
+same asset. Buys fill at the ask and sells at the bid of the book ticker, and every leg pays the taker commission of
+the account unless --fee is given. The triangles are ranked by the return of the cycle: a positive return means the
+rate through the intermediate asset beats the direct market.
+
+The implied column is the amount of the third asset received for one unit of the first through the second asset and
+the direct column is the amount of the third asset needed to buy one unit of the first back in the direct market.
+Depth is the most of the first asset the top of the books fill. Use a negative --min to include the closest misses.
+
+    arbitrage-scan --asset USDT --min 0.1
+		`,
+		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
+			defer resetFlags(cmd)
+			start := strings.ToUpper(start)
+			graph := newConversionGraph(symbols)
+			if _, ok := graph.edges[start]; start != "" && !ok {
+				return errors.New("unknown asset: " + start)
+			}
+
+			// fees default to the taker commission of the account
+			rate := fee
+			if !cmd.Flags().Changed("fee") {
+				var err error
+				if rate, err = getCommissionRate(client, true); err != nil {
+					return err
+				}
+			}
+
+			quotes, err := getBookQuotes(client)
+			if err != nil {
+				return err
+			}
+
+			var ranked []triangle
+			for _, t := range findTriangles(graph, quotes, rate, start) {
+				if t.gain*100 >= minimum && len(ranked) < limit {
+					ranked = append(ranked, t)
+				}
+			}
+
+			return output.Render(env, cmd, newArbitrageResult(rate, minimum/100, ranked))
+		},
+	}
+	command.Flags().StringVar(&start, "asset", "", "Only show triangles that start and end in this asset")
+	command.Flags().Float64Var(&fee, "fee", 0, "Commission rate per leg (defaults to the account taker commission)")
+	command.Flags().Float64Var(&minimum, "min", 0, "Minimum return in percent")
+	command.Flags().IntVar(&limit, "limit", 20, "Maximum number of triangles to show")
+	command.Flags().Lookup("asset").Annotations = map[string][]string{console.Suggestions: getQuoteAssetList(symbols)}
+	output.AddFlag(command)
+	scope.AddCommand(command)
+}
@@ -0,0 +1,244 @@
+package binance
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	binance "github.com/adshao/go-binance/v2"
+	"github.com/eliquious/console"
+	"github.com/eliquious/mercator/backtest"
+	"github.com/eliquious/mercator/output"
+)
+
+// backtestStrategies are the strategies available to the backtest command.
+var backtestStrategies = []string{"sma-cross", "rsi", "hold"}
+
+// klineIntervalDurations are the lengths of the kline intervals. Months are counted as 30 days.
+var klineIntervalDurations = map[string]time.Duration{
+	"1m":  time.Minute,
+	"3m":  3 * time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"30m": 30 * time.Minute,
+	"1h":  time.Hour,
+	"2h":  2 * time.Hour,
+	"4h":  4 * time.Hour,
+	"6h":  6 * time.Hour,
+	"8h":  8 * time.Hour,
+	"12h": 12 * time.Hour,
+	"1d":  24 * time.Hour,
+	"3d":  3 * 24 * time.Hour,
+	"1w":  7 * 24 * time.Hour,
+	"1M":  30 * 24 * time.Hour,
+}
+
+// sparkTicks are the block characters used to draw the equity curve.
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+func addBacktestCommand(scope *console.Scope, client *binance.Client, symbols []binance.Symbol) {
+	var strategyName, interval, from, to, equityFile string
+	var limit, fast, slow, period int
+	var capital, fee, oversold, overbought float64
+	command := &console.Command{
+		Use:   "backtest",
+		Short: "Replay klines for a symbol through a trading strategy",
+		Long: `Replays the klines of a symbol through a long-only strategy. Orders fill at the open of the candle after the
+signal and pay the taker commission of the account unless --fee is given. Any open position is closed at the last
+close. Strategies:
+
+    sma-cross  buy when the --fast SMA crosses above the --slow SMA and sell when it crosses below
+    rsi        buy when the RSI(--period) is below --oversold and sell when it is above --overbought
+    hold       buy on the first candle and hold
+
+    backtest BTCUSDT --strategy sma-cross --interval 1d --from 2020-01-01 --fast 20 --slow 50
+		`,
+		ValidateArgs:     console.MinimumArgs(1),
+		EagerSuggestions: true,
+		Suggestions: func(env *console.Environment, args []string) []string {
+			return getSymbolList(symbols)
+		},
+		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
+			defer resetFlags(cmd)
+			if len(args) != 1 {
+				return errors.New("requires 1 symbol")
+			}
+
+			symbol := strings.ToUpper(args[0])
+			if _, ok := getSymbolMap(symbols)[symbol]; !ok {
+				return errors.New("unknown symbol: " + args[0])
+			}
+			if !isKlineInterval(interval) {
+				return fmt.Errorf("invalid interval: %s (expected one of %s)", interval, strings.Join(klineIntervals, ", "))
+			}
+			if limit <= 0 || limit > maxKlineLimit {
+				return fmt.Errorf("limit must be between 1 and %d", maxKlineLimit)
+			}
+
+			var strategy backtest.Strategy
+			switch strategyName {
+			case "sma-cross":
+				if fast <= 0 || slow <= fast {
+					return errors.New("fast period must be greater than 0 and less than the slow period")
+				}
+				strategy = backtest.SMACross{Fast: fast, Slow: slow}
+			case "rsi":
+				if period <= 0 {
+					return errors.New("RSI period must be greater than 0")
+				} else if oversold >= overbought {
+					return errors.New("oversold level must be less than the overbought level")
+				}
+				strategy = backtest.RSIReversion{Period: period, Oversold: oversold, Overbought: overbought}
+			case "hold":
+				strategy = backtest.BuyAndHold{}
+			default:
+				return fmt.Errorf("unknown strategy: %s (expected one of %s)", strategyName, strings.Join(backtestStrategies, ", "))
+			}
+
+			var start, end time.Time
+			var err error
+			if cmd.Flags().Changed("from") {
+				if start, err = parseDate(from); err != nil {
+					return err
+				}
+			}
+			if cmd.Flags().Changed("to") {
+				if end, err = parseDate(to); err != nil {
+					return err
+				}
+				end = end.AddDate(0, 0, 1)
+			}
+			if !start.IsZero() && !end.IsZero() && !start.Before(end) {
+				return errors.New("from date must be before to date")
+			}
+
+			// fees default to the taker commission of the account
+			if !cmd.Flags().Changed("fee") {
+				if fee, err = getCommissionRate(client, true); err != nil {
+					return err
+				}
+			}
+
+			klines, err := listKlines(client, getMarketStore(env.Configuration), symbol, interval, start, end, limit)
+			if err != nil {
+				return err
+			}
+			candles, err := parseKlines(klines)
+			if err != nil {
+				return err
+			}
+
+			history := make([]backtest.Candle, len(candles))
+			for index, candle := range candles {
+				history[index] = backtest.Candle{
+					Time:   candle.openTime,
+					Open:   candle.open,
+					High:   candle.high,
+					Low:    candle.low,
+					Close:  candle.close,
+					Volume: candle.volume,
+				}
+			}
+
+			result, err := backtest.Run(history, strategy, backtest.Config{
+				Capital:        capital,
+				Fee:            fee,
+				PeriodsPerYear: float64(365*24*time.Hour) / float64(klineIntervalDurations[interval]),
+			})
+			if err != nil {
+				return err
+			}
+
+			if cmd.Flags().Changed("equity") {
+				if err := writeEquityCurve(equityFile, result.Equity); err != nil {
+					return err
+				}
+			}
+			if err := output.Render(env, cmd, newBacktestResult(symbol, interval, strategy, fee, history, result)); err != nil {
+				return err
+			}
+
+			// the note would break the other formats
+			if cmd.Flags().Changed("equity") && output.IsTable(env, cmd) {
+				fmt.Printf("Equity curve written to %s\n", equityFile)
+			}
+			return nil
+		},
+	}
+	command.Flags().StringVar(&strategyName, "strategy", "sma-cross", "Strategy: sma-cross, rsi or hold")
+	command.Flags().StringVar(&interval, "interval", "1d", "Candle interval")
+	command.Flags().StringVar(&from, "from", "", "Start date (YYYY-MM-DD)")
+	command.Flags().StringVar(&to, "to", "", "End date (YYYY-MM-DD)")
+	command.Flags().IntVar(&limit, "limit", maxKlineLimit, "Number of candles when no start date is given")
+	command.Flags().Float64Var(&capital, "capital", 1000, "Starting capital in the quote asset")
+	command.Flags().Float64Var(&fee, "fee", 0, "Commission rate per fill (defaults to the account taker commission)")
+	command.Flags().IntVar(&fast, "fast", 20, "Fast SMA period for sma-cross")
+	command.Flags().IntVar(&slow, "slow", 50, "Slow SMA period for sma-cross")
+	command.Flags().IntVar(&period, "period", 14, "RSI period for rsi")
+	command.Flags().Float64Var(&oversold, "oversold", 30, "RSI buy level for rsi")
+	command.Flags().Float64Var(&overbought, "overbought", 70, "RSI sell level for rsi")
+	command.Flags().StringVar(&equityFile, "equity", "", "Write the equity curve to a CSV file")
+	command.Flags().Lookup("strategy").Annotations = map[string][]string{console.Suggestions: backtestStrategies}
+	command.Flags().Lookup("interval").Annotations = map[string][]string{console.Suggestions: klineIntervals}
+	output.AddFlag(command)
+	scope.AddCommand(command)
+}
+
+// renderSparkline draws the equity curve in a single line. Long curves are sampled to fit the width.
+func renderSparkline(equity []backtest.EquityPoint, width int) string {
+	if width < 1 || len(equity) == 0 {
+		return ""
+	}
+
+	values := make([]float64, 0, width)
+	if len(equity) <= width {
+		for _, point := range equity {
+			values = append(values, point.Value)
+		}
+	} else if width == 1 {
+		values = append(values, equity[len(equity)-1].Value)
+	} else {
+		for index := 0; index < width; index++ {
+			values = append(values, equity[index*(len(equity)-1)/(width-1)].Value)
+		}
+	}
+
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, value := range values {
+		low = math.Min(low, value)
+		high = math.Max(high, value)
+	}
+
+	var builder strings.Builder
+	for _, value := range values {
+		tick := 0
+		if high > low {
+			tick = int((value - low) / (high - low) * float64(len(sparkTicks)-1))
+		}
+		builder.WriteRune(sparkTicks[tick])
+	}
+	return builder.String()
+}
+
+func writeEquityCurve(path string, equity []backtest.EquityPoint) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write([]string{"time", "equity"})
+	for _, point := range equity {
+		writer.Write([]string{point.Time.UTC().Format(time.RFC3339), formatAmount(point.Value)})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	return file.Close()
+}
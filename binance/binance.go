@@ -8,6 +8,7 @@ import (
 	"math"
 	"net/http"
 	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -16,23 +17,39 @@ import (
 	binance "github.com/adshao/go-binance/v2"
 	"github.com/eliquious/console"
 	"github.com/eliquious/console/colors"
+	"github.com/eliquious/mercator/output"
 	"github.com/gookit/color"
-	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/viper"
 )
 
-// NewBinanceExchangeScope creates a new scope for the Binance crypto exchange
-func NewBinanceExchangeScope() (*console.Scope, error) {
+// NewBinanceExchangeScope creates a new scope for the Binance crypto exchange and the binance object of the JS
+// interpreter. Setting BINANCE_TESTNET points the scope at the spot testnet using the BINANCE_TESTNET_API_KEY and
+// BINANCE_TESTNET_API_SECRET credentials.
+func NewBinanceExchangeScope(env *console.Environment) (*console.Scope, *ScriptAPI, error) {
 	apiKey := os.Getenv("BINANCE_API_KEY")
 	apiSecret := os.Getenv("BINANCE_API_SECRET")
+	scopeDescription := "Access Binance exchange information"
+
+	testnet, _ := strconv.ParseBool(os.Getenv("BINANCE_TESTNET"))
+	if testnet {
+		apiKey = os.Getenv("BINANCE_TESTNET_API_KEY")
+		apiSecret = os.Getenv("BINANCE_TESTNET_API_SECRET")
+		if apiKey == "" || apiSecret == "" {
+			return nil, nil, errors.New("Binance testnet requires env variables: BINANCE_TESTNET_API_KEY and BINANCE_TESTNET_API_SECRET")
+		}
 
-	if apiKey == "" || apiSecret == "" {
-		return nil, errors.New("Binance scope requires env variables: BINANCE_API_KEY and BINANCE_API_SECRET")
+		// the prompt shows the environment while the scope keeps its name so scripts run against either one
+		binance.UseTestnet = true
+		env.Prefix = " (testnet)" + env.Prefix
+		scopeDescription = "Access the Binance spot testnet (no real funds)"
+	} else if apiKey == "" || apiSecret == "" {
+		return nil, nil, errors.New("Binance scope requires env variables: BINANCE_API_KEY and BINANCE_API_SECRET")
 	}
 
 	proxyUsername := os.Getenv("PROXY_USER")
 	proxyPassword := os.Getenv("PROXY_PASS")
 	if proxyPassword == "" || proxyUsername == "" {
-		return nil, errors.New("Binance scope requires env variables: PROXY_USER and PROXY_PASS")
+		return nil, nil, errors.New("Binance scope requires env variables: PROXY_USER and PROXY_PASS")
 	}
 
 	client := binance.NewClient(apiKey, apiSecret)
@@ -44,25 +61,83 @@ func NewBinanceExchangeScope() (*console.Scope, error) {
 	resp, err := exch.Do(context.Background())
 	if err != nil {
 		log.Println(err)
-		return nil, errors.New("failed to list symbols")
+		return nil, nil, errors.New("failed to list symbols")
 	}
 
-	scope := console.NewScope("binance", "Access Binance exchange information")
+	// orders go to the exchange or the paper trading engine
+	traders := newTraders(client, resp.Symbols)
+
+	// price alerts are checked in the background
+	monitor := newAlertMonitor(client)
+
+	// the defaults are set up front since scripts use the configuration without entering the scope
+	setConfigDefaults(env.Configuration)
+	env.Configuration.Set("binance.testnet", testnet)
+	api := &ScriptAPI{env: env, client: client, traders: traders, symbols: resp.Symbols}
+
+	scope := console.NewScope("binance", scopeDescription)
+	scope.InitializeFunc = func(env *console.Environment) {
+		if env.Configuration.GetBool("binance.user_stream") && !traders.exchange.stream.running() {
+			if err := traders.exchange.stream.start(); err != nil {
+				color.Warn.Printf("failed to start the user data stream: %s\n", err)
+			}
+		}
+
+		path, err := getAlertsFile(env.Configuration)
+		if err != nil {
+			color.Warn.Println(err)
+		} else if alerts, err := loadAlerts(path); err != nil {
+			color.Warn.Println(err)
+		} else if len(alerts) > 0 {
+			monitor.start(path, env.Configuration.GetDuration("binance.alert_interval"))
+		}
+	}
 
 	addRateLimitCommand(scope, client)
 	addServerTimeCommand(scope, client)
 	addPriceCommands(scope, client, resp.Symbols)
-	addAccountCommands(scope, client, resp.Symbols)
+	addArbitrageScanCommand(scope, client, resp.Symbols)
+	addWatchCommand(scope, client, resp.Symbols)
+	addAccountCommands(scope, client, traders, resp.Symbols)
+	addUserStreamCommand(scope, traders)
+	addAlertCommand(scope, monitor, resp.Symbols)
+	addPortfolioCommand(scope, client, traders, resp.Symbols)
+	addPnLCommand(scope, client, resp.Symbols)
+	addTaxReportCommand(scope, client, resp.Symbols)
 	addDepthCommand(scope, client, resp.Symbols)
+	addKlinesCommand(scope, client, resp.Symbols)
+	addIndicatorsCommand(scope, client, resp.Symbols)
+	addBacktestCommand(scope, client, resp.Symbols)
+	addStoreCommands(scope, client, resp.Symbols)
 	addCalcSharesCommand(scope, client, resp.Symbols)
-	addRiskCommand(scope, client, resp.Symbols)
+	addRiskCommand(scope, traders, resp.Symbols)
+	addLimitOrderCommands(scope, traders, resp.Symbols)
+	addOrderManagementCommands(scope, traders, resp.Symbols)
+	addPaperCommands(scope, traders, resp.Symbols)
+	addOrderHistoryCommand(scope, client, resp.Symbols)
+	addTransferHistoryCommands(scope, client, resp.Symbols)
 	addCurrentValueCommand(scope, client, resp.Symbols)
 	addHistoricalMarketTrades(scope, client, resp.Symbols)
 	addRecentMarketTrades(scope, client, resp.Symbols)
 	addAssetDetail(scope, client, resp.Symbols)
+	addWithdrawCommands(scope, client, resp.Symbols)
 	addSymbolDetail(scope, client, resp.Symbols)
 	addFutureValueCommand(scope, client, resp.Symbols)
-	return scope, nil
+	addProfitCommand(scope, client, resp.Symbols)
+	addEstimateCommand(scope, client, resp.Symbols)
+	return scope, api, nil
+}
+
+// setConfigDefaults sets the defaults of the binance configuration.
+func setConfigDefaults(conf *viper.Viper) {
+	conf.SetDefault("binance.paper", false)
+	conf.SetDefault("binance.paper_file", filepath.Join(getConfigDir(conf), "paper.json"))
+	conf.SetDefault("binance.paper_fee", 0.001)
+	conf.SetDefault("binance.user_stream", false)
+	conf.SetDefault("binance.data_dir", filepath.Join(getConfigDir(conf), "data"))
+	conf.SetDefault("binance.alerts_file", filepath.Join(getConfigDir(conf), "alerts.json"))
+	conf.SetDefault("binance.alert_interval", defaultAlertInterval)
+	conf.SetDefault(scriptOrdersKey, false)
 }
 
 type binanceScope struct {
@@ -139,17 +214,14 @@ func addPriceCommands(scope *console.Scope, client *binance.Client, symbols []bi
 				return err
 			}
 
-			for _, arg := range args {
-				price, ok := currentPrices[arg]
-				if !ok {
-					fmt.Printf("%s:  %s\n", color.LightGreen.Render(arg), color.Red.Render("unknown symbol"))
-					continue
-				}
-				fmt.Printf("%s:  %s\n", color.LightGreen.Render(arg), price)
+			result, err := newPriceResult(currentPrices, args)
+			if err != nil {
+				return err
 			}
-			return nil
+			return output.Render(env, cmd, result)
 		},
 	}
+	output.AddFlag(priceCommand)
 	scope.AddCommand(priceCommand)
 
 	assetPricesCommand := &console.Command{
@@ -284,7 +356,7 @@ func getCurrentPrices(client *binance.Client) (map[string]string, error) {
 	return currentPrices, nil
 }
 
-func addAccountCommands(scope *console.Scope, client *binance.Client, symbols []binance.Symbol) {
+func addAccountCommands(scope *console.Scope, client *binance.Client, traders *traders, symbols []binance.Symbol) {
 	accountInfoCommand := &console.Command{
 		Use:   "account-info",
 		Short: "Show user account info",
@@ -313,32 +385,23 @@ func addAccountCommands(scope *console.Scope, client *binance.Client, symbols []
 		Use:   "account-balance",
 		Short: "Show user account balances",
 		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
-			exchange := client.NewGetAccountService()
-			resp, err := exchange.Do(context.Background())
+			t, err := traders.get(env, cmd)
+			if err != nil {
+				return err
+			}
+
+			resp, err := t.getAccount()
 			if err != nil {
 				return err
 			}
 
 			balances := resp.Balances
 			sort.Sort(OrderedBy(balances, byTotalBalance))
-
-			color.LightWhite.Println("\nAccount Balance(s):")
-			for index := 0; index < len(resp.Balances); index++ {
-				balance := resp.Balances[index]
-
-				f1, _ := strconv.ParseFloat(balance.Free, 64)
-				l1, _ := strconv.ParseFloat(balance.Locked, 64)
-
-				if f1 > 0 || l1 > 0 {
-					fmt.Printf("%s:\n", color.LightGreen.Render(balance.Asset))
-					fmt.Printf("  %s:     %s\n", color.LightYellow.Render("Free"), balance.Free)
-					fmt.Printf("  %s:   %s\n", color.LightYellow.Render("Locked"), balance.Locked)
-					fmt.Printf("  %s:    %0.8f\n", color.LightYellow.Render("Total"), f1+l1)
-				}
-			}
-			return nil
+			return output.Render(env, cmd, newBalanceResult(isPaper(t), balances))
 		},
 	}
+	accountBalanceCommand.Flags().Bool("dry-run", false, "Show the paper trading balances")
+	output.AddFlag(accountBalanceCommand)
 	scope.AddCommand(accountBalanceCommand)
 
 	var symbol string
@@ -354,98 +417,84 @@ func addAccountCommands(scope *console.Scope, client *binance.Client, symbols []
 			return []string{}
 		},
 		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
-			exchange := client.NewListTradesService()
-
-			// filter by symbol if set
-			if cmd.Flags().Changed("symbol") {
-				exchange = exchange.Symbol(symbol)
+			t, err := traders.get(env, cmd)
+			if err != nil {
+				return err
 			}
 
 			// add query limit
+			var queryLimit int
 			if cmd.Flags().Changed("limit") {
-				exchange = exchange.Limit(limit)
+				queryLimit = limit
 			}
 
-			trades, err := exchange.Do(context.Background())
+			trades, err := t.listTrades(symbol, queryLimit)
 			if err != nil {
 				return err
 			}
 
-			table := tablewriter.NewWriter(os.Stdout)
-			table.SetHeader([]string{"ID", "Timestamp", "Price", "Quantity", "Side"})
-
-			// ID, timestamp,price,qty
-			for index := 0; index < len(trades); index++ {
-				trade := trades[index]
-
-				var side string
-				if trade.IsBuyer {
-					side = color.Green.Render("BUY")
-				} else {
-					side = color.Red.Render("SELL")
-				}
-
-				row := []string{
-					strconv.Itoa(int(trade.ID)),
-					time.Unix(0, trade.Time*1e6).Local().Format("2006-01-02T15:04:05"),
-					trade.Price,
-					trade.Quantity,
-					side,
-				}
-				table.Append(row)
-			}
-			table.Render() // Send output
-
-			return nil
+			return output.Render(env, cmd, newTradesResult(isPaper(t), symbol, trades))
 		},
 	}
 	accountTradesCommand.Flags().StringVar(&symbol, "symbol", "", "Filter trades by this symbol")
 	accountTradesCommand.Flags().IntVar(&limit, "limit", 50, "Number of results to return")
+	accountTradesCommand.Flags().Bool("dry-run", false, "Show the paper trading trades")
+	output.AddFlag(accountTradesCommand)
 	scope.AddCommand(accountTradesCommand)
 }
 
 func addDepthCommand(scope *console.Scope, client *binance.Client, symbols []binance.Symbol) {
-	accountBalanceCommand := &console.Command{
+	var levels int
+	var live bool
+	depthCommand := &console.Command{
 		Use:              "depth",
 		Short:            "Show symbol depth",
+		Long:             "Shows the order book for a symbol. With --live the book is kept in sync with the depth stream until Ctrl-C.",
 		EagerSuggestions: true,
-		ValidateArgs:     console.ExactArgs(1),
+		ValidateArgs:     console.MinimumArgs(1),
 		Suggestions: func(env *console.Environment, args []string) []string {
 			return getSymbolList(symbols)
 		},
 		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
-			exchange := client.NewDepthService()
+			defer resetFlags(cmd)
+			if len(args) != 1 {
+				return errors.New("requires 1 symbol")
+			}
 
-			resp, err := exchange.Symbol(strings.ToUpper(args[0])).Limit(10).Do(context.Background())
-			if err != nil {
-				return err
+			if levels <= 0 {
+				return errors.New("levels must be positive")
 			}
 
-			fmt.Println("\n      ", args[0], "Order Book")
-			fmt.Println("------------------------------")
-			for index := len(resp.Asks) - 1; index >= 0; index-- {
-				ask := resp.Asks[index]
-				quant, err := strconv.ParseFloat(ask.Quantity, 64)
-				if err != nil {
-					return err
-				}
-				fmt.Printf(" % 12s %s\n", color.Magenta.Render(ask.Price), padLeft(fmt.Sprintf("%0.4f", quant), " ", 15))
+			symbol := strings.ToUpper(args[0])
+			info, ok := getSymbolMap(symbols)[symbol]
+			if !ok {
+				return errors.New("unknown symbol: " + args[0])
 			}
-			// fmt.Println("------------ -----------------")
-			fmt.Println()
-			for _, bid := range resp.Bids {
-				quant, err := strconv.ParseFloat(bid.Quantity, 64)
-				if err != nil {
-					return err
+
+			if live {
+				if !output.IsTable(env, cmd) {
+					return errors.New("--live only supports the table output")
 				}
-				fmt.Printf(" % 12s %s\n", color.Cyan.Render(bid.Price), padLeft(fmt.Sprintf("%0.4f", quant), " ", 15))
+				return runLiveDepth(client, info, levels)
 			}
-			fmt.Println("------------ -----------------")
-			fmt.Println()
-			return nil
+
+			exchange := client.NewDepthService()
+			resp, err := exchange.Symbol(symbol).Limit(getDepthLimit(levels)).Do(context.Background())
+			if err != nil {
+				return err
+			}
+
+			result, err := newDepthResult(symbol, resp, levels)
+			if err != nil {
+				return err
+			}
+			return output.Render(env, cmd, result)
 		},
 	}
-	scope.AddCommand(accountBalanceCommand)
+	depthCommand.Flags().IntVar(&levels, "levels", 10, "Number of price levels to show on each side")
+	depthCommand.Flags().BoolVar(&live, "live", false, "Stream the order book until Ctrl-C")
+	output.AddFlag(depthCommand)
+	scope.AddCommand(depthCommand)
 }
 
 func contains(s []string, e string) bool {
@@ -532,23 +581,41 @@ func addCalcSharesCommand(scope *console.Scope, client *binance.Client, symbols
 	scope.AddCommand(command)
 }
 
-func addRiskCommand(scope *console.Scope, client *binance.Client, symbols []binance.Symbol) {
-	var inv, entry, stop, ratio float64
+func addRiskCommand(scope *console.Scope, traders *traders, symbols []binance.Symbol) {
+	var inv, entry, stop, stopLimit, ratio, atrMultiplier float64
+	var interval string
 	command := &console.Command{
-		Use:          "risk",
-		Short:        "Calculate risk if bought and sold at certain prices",
-		ValidateArgs: console.ExactArgs(1),
+		Use:   "risk",
+		Short: "Calculate risk if bought and sold at certain prices",
+		Long: `Calculates the risk and earnings of a trade. The stop price is either given with --stop or suggested from
+the average true range with --atr, which places the stop that many ATRs below the entry.
+
+    risk BTCUSDT --inv 1000 --entry 50000 --stop 48000
+    risk BTCUSDT --inv 1000 --entry 50000 --atr 2 --interval 4h
+		`,
+		ValidateArgs: console.MinimumArgs(1),
 		Suggestions: func(env *console.Environment, args []string) []string {
 			return getSymbolList(symbols)
 		},
 		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
-			if entry <= 0 {
+			defer resetFlags(cmd)
+			if len(args) != 1 {
+				return errors.New("requires 1 symbol")
+			}
+
+			if !cmd.Flags().Changed("inv") || inv <= 0 {
+				return errors.New("investment amount is required")
+			}
+			if !cmd.Flags().Changed("entry") || entry <= 0 {
 				return errors.New("entry price is required")
 			}
-			if stop <= 0 {
-				return errors.New("stop price is required")
-			} else if stop >= entry {
-				return errors.New("stop price must be less than entry price")
+			if !cmd.Flags().Changed("stop") && !cmd.Flags().Changed("atr") {
+				return errors.New("stop price or ATR multiplier is required")
+			} else if cmd.Flags().Changed("stop") && cmd.Flags().Changed("atr") {
+				return errors.New("stop price and ATR multiplier cannot be used together")
+			}
+			if cmd.Flags().Changed("atr") && atrMultiplier <= 0 {
+				return errors.New("ATR multiplier must be greater than 0")
 			}
 			if ratio <= 0 {
 				return errors.New("risk/reward ratio must be greater than 0")
@@ -561,6 +628,28 @@ func addRiskCommand(scope *console.Scope, client *binance.Client, symbols []bina
 				return err
 			}
 
+			if cmd.Flags().Changed("atr") {
+				atr, err := getATR(traders.exchange.client, getMarketStore(env.Configuration), info.Symbol, interval)
+				if err != nil {
+					return err
+				}
+				stop = entry - atr*atrMultiplier
+				fmt.Printf("%s: %s %s (%s x ATR(%d) %s on %s)\n",
+					color.Green.Render("Stop"),
+					formatQuotePrice(info, stop),
+					color.LightBlue.Render(info.QuoteAsset),
+					formatAmount(atrMultiplier),
+					atrPeriod,
+					formatQuotePrice(info, atr),
+					interval,
+				)
+			}
+			if stop <= 0 {
+				return errors.New("stop price must be greater than 0")
+			} else if stop >= entry {
+				return errors.New("stop price must be less than entry price")
+			}
+
 			shares := inv / entry
 			fmt.Printf("%s: %s %s buys %s %s at %s\n",
 				color.Green.Render("Shares"),
@@ -582,14 +671,32 @@ func addRiskCommand(scope *console.Scope, client *binance.Client, symbols []bina
 				formatBasePrice(info, entry+(entry-stop)*ratio),
 				color.LightBlue.Render(info.BaseAsset),
 			)
+
+			// only place orders if asked to on this run
+			if place, _ := cmd.Flags().GetBool("place"); place && cmd.Flags().Changed("place") {
+				if !cmd.Flags().Changed("stop-limit") {
+					stopLimit = stop
+				}
+				t, err := traders.get(env, cmd)
+				if err != nil {
+					return err
+				}
+				return placeRiskOrders(env, t, info, inv, entry, stop, stopLimit, entry+(entry-stop)*ratio)
+			}
 			return nil
 		},
-		RequiredFlags: []string{"inv", "entry", "stop"},
+		RequiredFlags: []string{"inv", "entry"},
 	}
 	command.Flags().Float64Var(&inv, "inv", 0, "Investment amount")
 	command.Flags().Float64Var(&entry, "entry", 1, "Entry price")
 	command.Flags().Float64Var(&stop, "stop", 1, "Stop price")
 	command.Flags().Float64Var(&ratio, "ratio", 2, "Risk/reward ratio")
+	command.Flags().Bool("place", false, "Place a limit entry order and an OCO exit once filled")
+	command.Flags().Float64Var(&stopLimit, "stop-limit", 0, "Limit price of the stop order (defaults to the stop price)")
+	command.Flags().Bool("dry-run", false, "Send the orders to the paper trading engine")
+	command.Flags().Float64Var(&atrMultiplier, "atr", 0, "Suggest the stop this many ATRs below the entry")
+	command.Flags().StringVar(&interval, "interval", "1h", "Candle interval of the ATR")
+	command.Flags().Lookup("interval").Annotations = map[string][]string{console.Suggestions: klineIntervals}
 	scope.AddCommand(command)
 }
 
@@ -681,6 +788,7 @@ func addFutureValueCommand(scope *console.Scope, client *binance.Client, symbols
 func addHistoricalMarketTrades(scope *console.Scope, client *binance.Client, symbols []binance.Symbol) {
 	var symbol string
 	var limit int
+	var fromID int64
 	command := &console.Command{
 		Use:           "historical-market-trades",
 		Short:         "List the historical market trades",
@@ -692,54 +800,32 @@ func addHistoricalMarketTrades(scope *console.Scope, client *binance.Client, sym
 			return []string{}
 		},
 		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
-			exchange := client.NewHistoricalTradesService()
-
-			// filter by symbol if set
-			if cmd.Flags().Changed("symbol") {
-				exchange = exchange.Symbol(symbol)
+			defer resetFlags(cmd)
+			if !cmd.Flags().Changed("symbol") {
+				return errors.New("symbol is required")
 			}
 
-			// add query limit
-			if cmd.Flags().Changed("limit") {
-				exchange = exchange.Limit(limit)
+			// without a trade ID the latest trades are listed
+			start := int64(-1)
+			if cmd.Flags().Changed("from-id") {
+				start = fromID
 			}
-
-			trades, err := exchange.Do(context.Background())
+			trades, err := listTrades(client, getMarketStore(env.Configuration), strings.ToUpper(symbol), start, limit)
 			if err != nil {
 				return err
 			}
 
-			table := tablewriter.NewWriter(os.Stdout)
-			table.SetHeader([]string{"ID", "Timestamp", "Price", "Quantity"})
-
-			// ID, timestamp,price,qty
-			for index := 0; index < len(trades); index++ {
-				trade := trades[index]
-
-				row := []string{
-					strconv.Itoa(int(trade.ID)),
-					time.Unix(0, trade.Time*1e6).Local().Format("2006-01-02T15:04:05"),
-					trade.Price,
-					trade.Quantity,
-				}
-				table.Append(row)
-
-				// fmt.Printf("%s: %s\n  %s: %d\n  %s: %s\n\n",
-				// 	color.Green.Render("Interval"),
-				// 	limit.Interval,
-				// 	color.Green.Render("Limit"),
-				// 	limit.Limit,
-				// 	color.Green.Render("Type"),
-				// 	limit.RateLimitType,
-				// )
+			result, err := newMarketTradesResult(trades)
+			if err != nil {
+				return err
 			}
-			table.Render() // Send output
-
-			return nil
+			return output.Render(env, cmd, result)
 		},
 	}
 	command.Flags().StringVar(&symbol, "symbol", "", "Filter trades by this symbol")
 	command.Flags().IntVar(&limit, "limit", 50, "Number of results to return")
+	command.Flags().Int64Var(&fromID, "from-id", 0, "Trade ID to start from")
+	output.AddFlag(command)
 	scope.AddCommand(command)
 }
 
@@ -757,45 +843,26 @@ func addRecentMarketTrades(scope *console.Scope, client *binance.Client, symbols
 			return []string{}
 		},
 		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
-			exchange := client.NewRecentTradesService()
-
-			// filter by symbol if set
-			if cmd.Flags().Changed("symbol") {
-				exchange = exchange.Symbol(symbol)
+			defer resetFlags(cmd)
+			if !cmd.Flags().Changed("symbol") {
+				return errors.New("symbol is required")
 			}
 
-			// add query limit
-			if cmd.Flags().Changed("limit") {
-				exchange = exchange.Limit(limit)
-			}
-
-			trades, err := exchange.Do(context.Background())
+			trades, err := listTrades(client, getMarketStore(env.Configuration), strings.ToUpper(symbol), -1, limit)
 			if err != nil {
 				return err
 			}
 
-			table := tablewriter.NewWriter(os.Stdout)
-			table.SetHeader([]string{"ID", "Timestamp", "Price", "Quantity"})
-
-			// ID, timestamp,price,qty
-			for index := 0; index < len(trades); index++ {
-				trade := trades[index]
-
-				row := []string{
-					strconv.Itoa(int(trade.ID)),
-					time.Unix(0, trade.Time*1e6).Local().Format("2006-01-02T15:04:05"),
-					trade.Price,
-					trade.Quantity,
-				}
-				table.Append(row)
+			result, err := newMarketTradesResult(trades)
+			if err != nil {
+				return err
 			}
-			table.Render() // Send output
-
-			return nil
+			return output.Render(env, cmd, result)
 		},
 	}
 	command.Flags().StringVar(&symbol, "symbol", "", "Filter trades by this symbol")
 	command.Flags().IntVar(&limit, "limit", 50, "Number of results to return")
+	output.AddFlag(command)
 	scope.AddCommand(command)
 }
 
@@ -813,38 +880,30 @@ func addAssetDetail(scope *console.Scope, client *binance.Client, symbols []bina
 		},
 		EagerSuggestions: false,
 		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
-			exchange := client.NewGetAssetDetailService()
-
-			resp, err := exchange.Do(context.Background())
+			details, err := getAssetDetail(client, asset)
 			if err != nil {
 				return err
 			}
 
-			if details, ok := resp[asset]; ok {
-
-				var canDeposit, canWithdraw string
-				if details.DepositStatus {
-					canDeposit = colors.Green("true")
-				} else {
-					canDeposit = colors.Red("false")
-				}
-				if details.WithdrawStatus {
-					canWithdraw = colors.Green("true")
-				} else {
-					canWithdraw = colors.Red("false")
-				}
-
-				fmt.Printf("Deposit Status: %v\nDeposit Tip: %s\nWithdraw Status: %v\nMinimum Withdraw Amount: %f\nWithdraw Fee: %f\n",
-					canDeposit,
-					details.DepositTip,
-					canWithdraw,
-					details.MinWithdrawAmount,
-					details.WithdrawFee,
-				)
+			var canDeposit, canWithdraw string
+			if details.DepositStatus {
+				canDeposit = colors.Green("true")
 			} else {
-				return fmt.Errorf("unknown asset: %s", asset)
+				canDeposit = colors.Red("false")
+			}
+			if details.WithdrawStatus {
+				canWithdraw = colors.Green("true")
+			} else {
+				canWithdraw = colors.Red("false")
 			}
 
+			fmt.Printf("Deposit Status: %v\nDeposit Tip: %s\nWithdraw Status: %v\nMinimum Withdraw Amount: %f\nWithdraw Fee: %f\n",
+				canDeposit,
+				details.DepositTip,
+				canWithdraw,
+				details.MinWithdrawAmount,
+				details.WithdrawFee,
+			)
 			return nil
 		},
 	}
@@ -852,6 +911,21 @@ func addAssetDetail(scope *console.Scope, client *binance.Client, symbols []bina
 	scope.AddCommand(command)
 }
 
+func getAssetDetail(client *binance.Client, asset string) (binance.AssetDetail, error) {
+	exchange := client.NewGetAssetDetailService()
+
+	resp, err := exchange.Do(context.Background())
+	if err != nil {
+		return binance.AssetDetail{}, err
+	}
+
+	details, ok := resp[asset]
+	if !ok {
+		return details, fmt.Errorf("unknown asset: %s", asset)
+	}
+	return details, nil
+}
+
 func addSymbolDetail(scope *console.Scope, client *binance.Client, symbols []binance.Symbol) {
 	var symbol string
 	command := &console.Command{
@@ -868,39 +942,15 @@ func addSymbolDetail(scope *console.Scope, client *binance.Client, symbols []bin
 		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
 			s := getSymbolMap(symbols)
 
-			if details, ok := s[symbol]; ok {
-				// Status                 string                   `json:"status"`
-				// BaseAsset              string                   `json:"baseAsset"`
-				// BaseAssetPrecision     int                      `json:"baseAssetPrecision"`
-				// QuoteAsset             string                   `json:"quoteAsset"`
-				// QuotePrecision         int                      `json:"quotePrecision"`
-				// OrderTypes             []string                 `json:"orderTypes"`
-				// IcebergAllowed         bool                     `json:"icebergAllowed"`
-				// OcoAllowed             bool                     `json:"ocoAllowed"`
-				// IsSpotTradingAllowed   bool                     `json:"isSpotTradingAllowed"`
-				// IsMarginTradingAllowed bool                     `json:"isMarginTradingAllowed"`
-
-				fmt.Printf("Symbol Status: %v\nBase Asset: %s\nBase Asset Precision: %d\nQuote Asset: %s\nQuote Precision: %d\nIceberg Allowed: %s\nOCO Orders Allowed: %s\nSpot Trading: %s\nMargin Trading: %s\n",
-					details.Status,
-					details.BaseAsset,
-					details.BaseAssetPrecision,
-					details.QuoteAsset,
-					details.QuotePrecision,
-					formatBoolean(details.IcebergAllowed),
-					formatBoolean(details.OcoAllowed),
-					formatBoolean(details.IsSpotTradingAllowed),
-					formatBoolean(details.IsMarginTradingAllowed),
-				)
-
-				fmt.Printf("\nSupported Order Types:\n%s\n\n", strings.Join(details.OrderTypes, "\n"))
-			} else {
+			details, ok := s[symbol]
+			if !ok {
 				return fmt.Errorf("unknown symbol: %s", symbol)
 			}
-
-			return nil
+			return output.Render(env, cmd, newSymbolDetailResult(details))
 		},
 	}
 	command.Flags().StringVar(&symbol, "symbol", "", "Get the details for the symbol")
+	output.AddFlag(command)
 	scope.AddCommand(command)
 }
 
@@ -0,0 +1,77 @@
+package binance
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/eliquious/console"
+	"github.com/gookit/color"
+)
+
+// Confirmation settings for running without the prompt.
+const (
+	// AssumeYesKey answers yes to every confirmation except withdrawals. It is set by the --yes flag.
+	AssumeYesKey = "assume_yes"
+
+	// NoPromptKey makes confirmations fail instead of reading stdin. It is set when a script is read from stdin so
+	// the answer is not taken from the script.
+	NoPromptKey = "no_prompt"
+)
+
+// errNotConfirmed is returned when the user does not confirm.
+var errNotConfirmed = errors.New("not confirmed")
+
+// confirm asks the user a yes/no question. Anything other than "y" or "yes" is treated as no and returns an error so
+// commands run from scripts fail instead of exiting successfully.
+func confirm(env *console.Environment, question string) error {
+	if env.Configuration.GetBool(AssumeYesKey) {
+		return nil
+	}
+
+	answer, err := prompt(env, fmt.Sprintf("%s [y/N]: ", question))
+	if err != nil {
+		return fmt.Errorf("%s (run with --yes to confirm)", err)
+	}
+
+	answer = strings.ToLower(answer)
+	if answer != "y" && answer != "yes" {
+		return errNotConfirmed
+	}
+	return nil
+}
+
+// prompt asks the user for a line of input. It fails if stdin is not a terminal or is used by a script, since an
+// answer cannot be given and end of file would otherwise read as no.
+func prompt(env *console.Environment, text string) (string, error) {
+	if env.Configuration.GetBool(NoPromptKey) || !isTerminal(os.Stdin) {
+		return "", errors.New("cannot ask for confirmation without a terminal")
+	}
+
+	answer, err := readLine(text)
+	if err == io.EOF {
+		return "", errors.New("no answer")
+	}
+	return answer, err
+}
+
+// isTerminal checks if the file is a terminal.
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// readLine prints the prompt and reads a single line from stdin.
+func readLine(prompt string) (string, error) {
+	fmt.Print(color.LightYellow.Render(prompt))
+
+	reader := bufio.NewReader(os.Stdin)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
@@ -0,0 +1,256 @@
+package binance
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	binance "github.com/adshao/go-binance/v2"
+	"github.com/gookit/color"
+)
+
+// depthLimits are the order book sizes supported by the depth endpoint.
+var depthLimits = []int{5, 10, 20, 50, 100, 500, 1000, 5000}
+
+// getDepthLimit returns the smallest supported depth limit that includes the number of levels.
+func getDepthLimit(levels int) int {
+	for _, limit := range depthLimits {
+		if limit >= levels {
+			return limit
+		}
+	}
+	return depthLimits[len(depthLimits)-1]
+}
+
+// orderBookLevel is a price level in the order book.
+type orderBookLevel struct {
+	price    float64
+	quantity float64
+}
+
+// orderBook is a local order book kept in sync with the diff depth stream.
+type orderBook struct {
+	lastUpdateID int64
+	bids         map[float64]float64
+	asks         map[float64]float64
+}
+
+func newOrderBook(snapshot *binance.DepthResponse) (*orderBook, error) {
+	book := &orderBook{
+		lastUpdateID: snapshot.LastUpdateID,
+		bids:         make(map[float64]float64, len(snapshot.Bids)),
+		asks:         make(map[float64]float64, len(snapshot.Asks)),
+	}
+	for _, bid := range snapshot.Bids {
+		if err := book.update(book.bids, bid.Price, bid.Quantity); err != nil {
+			return nil, err
+		}
+	}
+	for _, ask := range snapshot.Asks {
+		if err := book.update(book.asks, ask.Price, ask.Quantity); err != nil {
+			return nil, err
+		}
+	}
+	return book, nil
+}
+
+func (b *orderBook) update(side map[float64]float64, price, quantity string) error {
+	p, err := strconv.ParseFloat(price, 64)
+	if err != nil {
+		return fmt.Errorf("could not parse price: %s", price)
+	}
+	q, err := strconv.ParseFloat(quantity, 64)
+	if err != nil {
+		return fmt.Errorf("could not parse quantity: %s", quantity)
+	}
+
+	// a quantity of 0 removes the level
+	if q == 0 {
+		delete(side, p)
+	} else {
+		side[p] = q
+	}
+	return nil
+}
+
+// apply applies a diff depth event. Events older than the book are ignored. It returns false if an event was
+// missed and the book needs to be rebuilt from a new snapshot.
+func (b *orderBook) apply(event *binance.WsDepthEvent) (bool, error) {
+	if event.UpdateID <= b.lastUpdateID {
+		return true, nil
+	}
+	if event.FirstUpdateID > b.lastUpdateID+1 {
+		return false, nil
+	}
+
+	for _, bid := range event.Bids {
+		if err := b.update(b.bids, bid.Price, bid.Quantity); err != nil {
+			return false, err
+		}
+	}
+	for _, ask := range event.Asks {
+		if err := b.update(b.asks, ask.Price, ask.Quantity); err != nil {
+			return false, err
+		}
+	}
+	b.lastUpdateID = event.UpdateID
+	return true, nil
+}
+
+// top returns the best levels of each side. Bids are sorted high to low and asks low to high.
+func (b *orderBook) top(levels int) ([]orderBookLevel, []orderBookLevel) {
+	return sortLevels(b.bids, levels, true), sortLevels(b.asks, levels, false)
+}
+
+func sortLevels(side map[float64]float64, levels int, descending bool) []orderBookLevel {
+	sorted := make([]orderBookLevel, 0, len(side))
+	for price, quantity := range side {
+		sorted = append(sorted, orderBookLevel{price, quantity})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if descending {
+			return sorted[i].price > sorted[j].price
+		}
+		return sorted[i].price < sorted[j].price
+	})
+
+	if len(sorted) > levels {
+		sorted = sorted[:levels]
+	}
+	return sorted
+}
+
+// runLiveDepth keeps a local order book in sync with the diff depth stream and renders a ladder until Ctrl-C. The
+// stream is buffered while the snapshot is fetched, then events up to the snapshot's lastUpdateId are dropped.
+func runLiveDepth(client *binance.Client, info binance.Symbol, levels int) error {
+	var mu sync.Mutex
+	var book *orderBook
+	var buffered []*binance.WsDepthEvent
+	var streamErr error
+
+	resync := func() error {
+		snapshot, err := client.NewDepthService().Symbol(info.Symbol).Limit(1000).Do(context.Background())
+		if err != nil {
+			return err
+		}
+
+		newBook, err := newOrderBook(snapshot)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		book = newBook
+		for _, event := range buffered {
+			if ok, err := book.apply(event); err != nil {
+				return err
+			} else if !ok {
+				book = nil
+				return nil
+			}
+		}
+		buffered = nil
+		return nil
+	}
+
+	done, stop, err := binance.WsDepthServe100Ms(info.Symbol, func(event *binance.WsDepthEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// buffer events until the snapshot has been loaded
+		if book == nil {
+			buffered = append(buffered, event)
+			return
+		}
+
+		ok, err := book.apply(event)
+		if err != nil {
+			streamErr = err
+		} else if !ok {
+			// an event was missed so the book is rebuilt
+			book = nil
+			buffered = append(buffered, event)
+		}
+	}, func(err error) {
+		mu.Lock()
+		streamErr = err
+		mu.Unlock()
+	})
+	if err != nil {
+		return err
+	}
+	defer close(stop)
+
+	if err := resync(); err != nil {
+		return err
+	}
+
+	fmt.Println("Press Ctrl-C to stop")
+	var view liveView
+	runLive(done, func() {
+		mu.Lock()
+		if streamErr != nil {
+			mu.Unlock()
+			return
+		}
+		if book == nil {
+			mu.Unlock()
+			if err := resync(); err != nil {
+				mu.Lock()
+				streamErr = err
+				mu.Unlock()
+			}
+			return
+		}
+		bids, asks := book.top(levels)
+		mu.Unlock()
+
+		view.render(renderLadder(info, bids, asks))
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	return streamErr
+}
+
+// renderLadder renders the order book with the cumulative size, spread and imbalance.
+func renderLadder(info binance.Symbol, bids, asks []orderBookLevel) string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "\n       %s Order Book\n", info.Symbol)
+	fmt.Fprintf(&buf, " %16s %16s %16s\n", "Price", "Size", "Total")
+	fmt.Fprintln(&buf, "--------------------------------------------------")
+
+	var askTotal float64
+	askTotals := make([]float64, len(asks))
+	for index, ask := range asks {
+		askTotal += ask.quantity
+		askTotals[index] = askTotal
+	}
+	for index := len(asks) - 1; index >= 0; index-- {
+		fmt.Fprintf(&buf, " %s %16.4f %16.4f\n", color.Magenta.Render(fmt.Sprintf("%16s", formatQuotePrice(info, asks[index].price))), asks[index].quantity, askTotals[index])
+	}
+
+	if len(bids) > 0 && len(asks) > 0 {
+		spread := asks[0].price - bids[0].price
+		fmt.Fprintf(&buf, "    %s %s (%0.4f%%)\n", color.LightWhite.Render("Spread:"), formatQuotePrice(info, spread), spread/asks[0].price*100)
+	} else {
+		fmt.Fprintln(&buf)
+	}
+
+	var bidTotal float64
+	for _, bid := range bids {
+		bidTotal += bid.quantity
+		fmt.Fprintf(&buf, " %s %16.4f %16.4f\n", color.Cyan.Render(fmt.Sprintf("%16s", formatQuotePrice(info, bid.price))), bid.quantity, bidTotal)
+	}
+	fmt.Fprintln(&buf, "--------------------------------------------------")
+
+	if bidTotal+askTotal > 0 {
+		imbalance := (bidTotal - askTotal) / (bidTotal + askTotal)
+		fmt.Fprintf(&buf, "    %s %s\n", color.LightWhite.Render("Imbalance:"), formatChange(fmt.Sprint(imbalance), fmt.Sprintf("%+0.2f%%", imbalance*100)))
+	}
+	return buf.String()
+}
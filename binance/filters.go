@@ -0,0 +1,165 @@
+package binance
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	binance "github.com/adshao/go-binance/v2"
+)
+
+// symbolFilters contains the parsed trading filters for a symbol.
+type symbolFilters struct {
+	minPrice    float64
+	maxPrice    float64
+	tickSize    float64
+	minQuantity float64
+	maxQuantity float64
+	stepSize    float64
+	minNotional float64
+
+	pricePrecision    int
+	quantityPrecision int
+}
+
+// getSymbolFilters parses the PRICE_FILTER, LOT_SIZE and MIN_NOTIONAL filters for the symbol.
+func getSymbolFilters(symbol binance.Symbol) (symbolFilters, error) {
+	var filters symbolFilters
+	var err error
+
+	priceFilter := symbol.PriceFilter()
+	if priceFilter == nil {
+		return filters, fmt.Errorf("%s has no price filter", symbol.Symbol)
+	}
+	if filters.minPrice, err = parseFilterValue("minPrice", priceFilter.MinPrice); err != nil {
+		return filters, err
+	}
+	if filters.maxPrice, err = parseFilterValue("maxPrice", priceFilter.MaxPrice); err != nil {
+		return filters, err
+	}
+	if filters.tickSize, err = parseFilterValue("tickSize", priceFilter.TickSize); err != nil {
+		return filters, err
+	}
+	filters.pricePrecision = getStepPrecision(priceFilter.TickSize)
+
+	lotSizeFilter := symbol.LotSizeFilter()
+	if lotSizeFilter == nil {
+		return filters, fmt.Errorf("%s has no lot size filter", symbol.Symbol)
+	}
+	if filters.minQuantity, err = parseFilterValue("minQty", lotSizeFilter.MinQuantity); err != nil {
+		return filters, err
+	}
+	if filters.maxQuantity, err = parseFilterValue("maxQty", lotSizeFilter.MaxQuantity); err != nil {
+		return filters, err
+	}
+	if filters.stepSize, err = parseFilterValue("stepSize", lotSizeFilter.StepSize); err != nil {
+		return filters, err
+	}
+	filters.quantityPrecision = getStepPrecision(lotSizeFilter.StepSize)
+
+	// not every symbol has a minimum notional
+	if minNotionalFilter := symbol.MinNotionalFilter(); minNotionalFilter != nil {
+		if filters.minNotional, err = parseFilterValue("minNotional", minNotionalFilter.MinNotional); err != nil {
+			return filters, err
+		}
+	}
+	return filters, nil
+}
+
+func parseFilterValue(name, value string) (float64, error) {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse filter value: %s %s", name, value)
+	}
+	return f, nil
+}
+
+// getStepPrecision returns the number of decimals required by a step such as "0.00100000".
+func getStepPrecision(step string) int {
+	index := strings.Index(step, ".")
+	if index < 0 {
+		return 0
+	}
+	return len(strings.TrimRight(step[index+1:], "0"))
+}
+
+// isStepMultiple checks if the value is a multiple of the step starting from the minimum.
+func isStepMultiple(value, min, step float64) bool {
+	if step <= 0 {
+		return true
+	}
+	steps := (value - min) / step
+	return math.Abs(steps-math.Round(steps)) < 1e-6
+}
+
+// validatePrice checks the price against the PRICE_FILTER.
+func (f symbolFilters) validatePrice(price float64) error {
+	if f.minPrice > 0 && price < f.minPrice {
+		return fmt.Errorf("price %s is below the minimum price of %s", f.formatPrice(price), f.formatPrice(f.minPrice))
+	}
+	if f.maxPrice > 0 && price > f.maxPrice {
+		return fmt.Errorf("price %s is above the maximum price of %s", f.formatPrice(price), f.formatPrice(f.maxPrice))
+	}
+	if !isStepMultiple(price, f.minPrice, f.tickSize) {
+		return fmt.Errorf("price %v is not a multiple of the tick size %s", price, f.formatPrice(f.tickSize))
+	}
+	return nil
+}
+
+// validateQuantity checks the quantity against the LOT_SIZE filter.
+func (f symbolFilters) validateQuantity(quantity float64) error {
+	if quantity < f.minQuantity {
+		return fmt.Errorf("quantity %s is below the minimum quantity of %s", f.formatQuantity(quantity), f.formatQuantity(f.minQuantity))
+	}
+	if f.maxQuantity > 0 && quantity > f.maxQuantity {
+		return fmt.Errorf("quantity %s is above the maximum quantity of %s", f.formatQuantity(quantity), f.formatQuantity(f.maxQuantity))
+	}
+	if !isStepMultiple(quantity, f.minQuantity, f.stepSize) {
+		return fmt.Errorf("quantity %v is not a multiple of the step size %s", quantity, f.formatQuantity(f.stepSize))
+	}
+	return nil
+}
+
+// validateNotional checks the order value against the MIN_NOTIONAL filter.
+func (f symbolFilters) validateNotional(price, quantity float64) error {
+	if price*quantity < f.minNotional {
+		return fmt.Errorf("order value %v is below the minimum notional of %v", price*quantity, f.minNotional)
+	}
+	return nil
+}
+
+// validateOrder checks the price and quantity against all the filters.
+func (f symbolFilters) validateOrder(price, quantity float64) error {
+	if err := f.validatePrice(price); err != nil {
+		return err
+	}
+	if err := f.validateQuantity(quantity); err != nil {
+		return err
+	}
+	return f.validateNotional(price, quantity)
+}
+
+// roundQuantity rounds the quantity down to the nearest step size.
+func (f symbolFilters) roundQuantity(quantity float64) float64 {
+	if f.stepSize <= 0 {
+		return quantity
+	}
+	return math.Floor(quantity/f.stepSize+1e-9) * f.stepSize
+}
+
+// roundPrice rounds the price down to the nearest tick size.
+func (f symbolFilters) roundPrice(price float64) float64 {
+	if f.tickSize <= 0 {
+		return price
+	}
+	return math.Floor(price/f.tickSize+1e-9) * f.tickSize
+}
+
+func (f symbolFilters) formatPrice(price float64) string {
+	return strconv.FormatFloat(price, 'f', f.pricePrecision, 64)
+}
+
+func (f symbolFilters) formatQuantity(quantity float64) string {
+	return strconv.FormatFloat(quantity, 'f', f.quantityPrecision, 64)
+}
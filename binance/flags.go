@@ -0,0 +1,26 @@
+package binance
+
+import (
+	"strings"
+
+	"github.com/eliquious/console"
+	"github.com/spf13/pflag"
+)
+
+// resetFlags sets every flag of the command back to its default. console only resets Changed between runs, so
+// without it the variables bound to the flags keep the values of an earlier run. Commands with bound flags defer it
+// at the start of Run.
+func resetFlags(cmd *console.Command) {
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			// setting a slice flag appends to the values of an earlier run
+			values := []string{}
+			if def := strings.Trim(f.DefValue, "[]"); def != "" {
+				values = strings.Split(def, ",")
+			}
+			slice.Replace(values)
+			return
+		}
+		f.Value.Set(f.DefValue)
+	})
+}
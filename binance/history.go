@@ -0,0 +1,385 @@
+package binance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	binance "github.com/adshao/go-binance/v2"
+	"github.com/eliquious/console"
+	"github.com/eliquious/mercator/output"
+)
+
+// maxOrderHistoryLimit is the maximum number of orders Binance returns per request.
+const maxOrderHistoryLimit = 1000
+
+const dateFormat = "2006-01-02"
+
+func addOrderHistoryCommand(scope *console.Scope, client *binance.Client, symbols []binance.Symbol) {
+	var symbol, from, to string
+	var statuses []string
+	command := &console.Command{
+		Use:   "order-history",
+		Short: "List historical orders for a symbol",
+		Long: `Lists all the orders for a symbol between two dates. Results are paged automatically so a large date range
+can be pulled in one go. Dates are in the local timezone and the end date is inclusive.
+
+    order-history --symbol BTCUSDT --from 2020-01-01 --to 2020-12-31 --status FILLED,CANCELED
+		`,
+		RequiredFlags: []string{"symbol"},
+		Suggestions: func(env *console.Environment, args []string) []string {
+			if contains(args, "--symbol") && len(args) > 2 {
+				return getSymbolList(symbols)
+			}
+			return []string{}
+		},
+		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
+			defer resetFlags(cmd)
+			if !cmd.Flags().Changed("symbol") {
+				return errors.New("symbol is required")
+			}
+
+			var start, end time.Time
+			var err error
+			if cmd.Flags().Changed("from") {
+				if start, err = parseDate(from); err != nil {
+					return err
+				}
+			}
+			if cmd.Flags().Changed("to") {
+				if end, err = parseDate(to); err != nil {
+					return err
+				}
+				end = end.AddDate(0, 0, 1)
+			}
+			if !start.IsZero() && !end.IsZero() && !start.Before(end) {
+				return errors.New("from date must be before to date")
+			}
+
+			orders, err := listAllOrders(client, strings.ToUpper(symbol), start, end)
+			if err != nil {
+				return err
+			}
+
+			if cmd.Flags().Changed("status") {
+				orders = filterOrdersByStatus(orders, statuses)
+			}
+
+			result := newOrdersResult(false, orders)
+			result.empty = "No orders found"
+			result.count = true
+			return output.Render(env, cmd, result)
+		},
+	}
+	command.Flags().StringVar(&symbol, "symbol", "", "List orders for this symbol")
+	command.Flags().StringVar(&from, "from", "", "Start date (YYYY-MM-DD)")
+	command.Flags().StringVar(&to, "to", "", "End date (YYYY-MM-DD)")
+	command.Flags().StringSliceVar(&statuses, "status", nil, "Filter by order status (NEW, FILLED, CANCELED, ...)")
+	output.AddFlag(command)
+	scope.AddCommand(command)
+}
+
+// listAllOrders pages through the order history of a symbol. The first page starts at the start time (or the first
+// order if zero) and the following pages continue from the last order ID until the end time is passed.
+func listAllOrders(client *binance.Client, symbol string, start, end time.Time) ([]*binance.Order, error) {
+	var orders []*binance.Order
+	var nextID int64
+
+	for {
+		exchange := client.NewListOrdersService().Symbol(symbol).Limit(maxOrderHistoryLimit)
+		if len(orders) == 0 && !start.IsZero() {
+			exchange = exchange.StartTime(toMillis(start))
+		} else {
+			exchange = exchange.OrderID(nextID)
+		}
+
+		page, err := exchange.Do(context.Background())
+		if err != nil {
+			return nil, err
+		}
+
+		for _, order := range page {
+			if !end.IsZero() && order.Time >= toMillis(end) {
+				return orders, nil
+			}
+			orders = append(orders, order)
+			nextID = order.OrderID + 1
+		}
+
+		if len(page) < maxOrderHistoryLimit {
+			return orders, nil
+		}
+	}
+}
+
+func filterOrdersByStatus(orders []*binance.Order, statuses []string) []*binance.Order {
+	filtered := make([]*binance.Order, 0, len(orders))
+	for _, order := range orders {
+		for _, status := range statuses {
+			if strings.EqualFold(string(order.Status), strings.TrimSpace(status)) {
+				filtered = append(filtered, order)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+func parseDate(value string) (time.Time, error) {
+	t, err := time.ParseInLocation(dateFormat, value, time.Local)
+	if err != nil {
+		return t, fmt.Errorf("invalid date: %s (expected YYYY-MM-DD)", value)
+	}
+	return t, nil
+}
+
+func toMillis(t time.Time) int64 {
+	return t.UnixNano() / 1e6
+}
+
+// maxTransferHistoryWindow is the maximum time range Binance allows per deposit or withdraw history request.
+const maxTransferHistoryWindow = 90 * 24 * time.Hour
+
+var depositStatuses = map[int]string{
+	0: "PENDING",
+	1: "SUCCESS",
+	6: "CREDITED",
+}
+
+var withdrawStatuses = map[int]string{
+	0: "EMAIL_SENT",
+	1: "CANCELLED",
+	2: "AWAITING_APPROVAL",
+	3: "REJECTED",
+	4: "PROCESSING",
+	5: "FAILURE",
+	6: "COMPLETED",
+}
+
+// transferFilter contains the flags shared by the deposit and withdraw history commands.
+type transferFilter struct {
+	asset  string
+	status string
+	from   string
+	to     string
+}
+
+func (f *transferFilter) addFlags(cmd *console.Command, statuses map[int]string) {
+	names := make([]string, 0, len(statuses))
+	for _, name := range statuses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cmd.Flags().StringVar(&f.asset, "asset", "", "Filter by asset")
+	cmd.Flags().StringVar(&f.status, "status", "", "Filter by status ("+strings.Join(names, ", ")+")")
+	cmd.Flags().StringVar(&f.from, "from", "", "Start date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&f.to, "to", "", "End date (YYYY-MM-DD)")
+	cmd.Flags().Lookup("status").Annotations = map[string][]string{console.Suggestions: names}
+}
+
+// parseStatus converts a status name or code into the status code used by the API.
+func (f *transferFilter) parseStatus(statuses map[int]string) (int, error) {
+	if code, err := strconv.Atoi(f.status); err == nil {
+		if _, ok := statuses[code]; ok {
+			return code, nil
+		}
+	}
+	for code, name := range statuses {
+		if strings.EqualFold(name, f.status) {
+			return code, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown status: %s", f.status)
+}
+
+// parseWindows splits the date range into windows no longer than the maximum allowed by the API. A nil result
+// means no date range was given and the API default is used.
+func (f *transferFilter) parseWindows(cmd *console.Command) ([][2]time.Time, error) {
+	if !cmd.Flags().Changed("from") && !cmd.Flags().Changed("to") {
+		return nil, nil
+	}
+
+	end := time.Now()
+	if cmd.Flags().Changed("to") {
+		t, err := parseDate(f.to)
+		if err != nil {
+			return nil, err
+		}
+		end = t.AddDate(0, 0, 1)
+	}
+
+	start := end.Add(-maxTransferHistoryWindow)
+	if cmd.Flags().Changed("from") {
+		t, err := parseDate(f.from)
+		if err != nil {
+			return nil, err
+		}
+		start = t
+	}
+
+	if !start.Before(end) {
+		return nil, errors.New("from date must be before to date")
+	}
+
+	var windows [][2]time.Time
+	for windowStart := start; windowStart.Before(end); windowStart = windowStart.Add(maxTransferHistoryWindow) {
+		windowEnd := windowStart.Add(maxTransferHistoryWindow)
+		if windowEnd.After(end) {
+			windowEnd = end
+		}
+		windows = append(windows, [2]time.Time{windowStart, windowEnd})
+	}
+	return windows, nil
+}
+
+func addTransferHistoryCommands(scope *console.Scope, client *binance.Client, symbols []binance.Symbol) {
+	assetSuggestions := func(env *console.Environment, args []string) []string {
+		if contains(args, "--asset") && len(args) > 2 {
+			return getBaseAssetList(symbols)
+		}
+		return []string{}
+	}
+
+	var deposits transferFilter
+	depositCommand := &console.Command{
+		Use:         "deposit-history",
+		Short:       "List deposit history",
+		Suggestions: assetSuggestions,
+		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
+			windows, err := deposits.parseWindows(cmd)
+			if err != nil {
+				return err
+			}
+
+			var status int
+			if cmd.Flags().Changed("status") {
+				if status, err = deposits.parseStatus(depositStatuses); err != nil {
+					return err
+				}
+			}
+
+			newService := func() *binance.ListDepositsService {
+				exchange := client.NewListDepositsService()
+				if cmd.Flags().Changed("asset") {
+					exchange = exchange.Asset(strings.ToUpper(deposits.asset))
+				}
+				if cmd.Flags().Changed("status") {
+					exchange = exchange.Status(status)
+				}
+				return exchange
+			}
+
+			var history []*binance.Deposit
+			if windows == nil {
+				if history, err = newService().Do(context.Background()); err != nil {
+					return err
+				}
+			}
+			for _, window := range windows {
+				page, err := newService().StartTime(toMillis(window[0])).EndTime(toMillis(window[1])).Do(context.Background())
+				if err != nil {
+					return err
+				}
+				history = append(history, page...)
+			}
+
+			sort.Slice(history, func(i, j int) bool { return history[i].InsertTime > history[j].InsertTime })
+			return output.Render(env, cmd, newDepositsResult(history))
+		},
+	}
+	deposits.addFlags(depositCommand, depositStatuses)
+	output.AddFlag(depositCommand)
+	scope.AddCommand(depositCommand)
+
+	var withdraws transferFilter
+	withdrawCommand := &console.Command{
+		Use:         "withdraw-history",
+		Short:       "List withdrawal history",
+		Suggestions: assetSuggestions,
+		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
+			windows, err := withdraws.parseWindows(cmd)
+			if err != nil {
+				return err
+			}
+
+			var status int
+			if cmd.Flags().Changed("status") {
+				if status, err = withdraws.parseStatus(withdrawStatuses); err != nil {
+					return err
+				}
+			}
+
+			newService := func() *binance.ListWithdrawsService {
+				exchange := client.NewListWithdrawsService()
+				if cmd.Flags().Changed("asset") {
+					exchange = exchange.Asset(strings.ToUpper(withdraws.asset))
+				}
+				if cmd.Flags().Changed("status") {
+					exchange = exchange.Status(status)
+				}
+				return exchange
+			}
+
+			var history []*binance.Withdraw
+			if windows == nil {
+				if history, err = newService().Do(context.Background()); err != nil {
+					return err
+				}
+			}
+			for _, window := range windows {
+				page, err := newService().StartTime(toMillis(window[0])).EndTime(toMillis(window[1])).Do(context.Background())
+				if err != nil {
+					return err
+				}
+				history = append(history, page...)
+			}
+
+			sort.Slice(history, func(i, j int) bool { return history[i].ApplyTime > history[j].ApplyTime })
+			return output.Render(env, cmd, newWithdrawalsResult(history))
+		},
+	}
+	withdraws.addFlags(withdrawCommand, withdrawStatuses)
+	output.AddFlag(withdrawCommand)
+	scope.AddCommand(withdrawCommand)
+}
+
+func formatTransferStatus(statuses map[int]string, status int) string {
+	if name, ok := statuses[status]; ok {
+		return name
+	}
+	return strconv.Itoa(status)
+}
+
+// maxTradeHistoryLimit is the maximum number of trades Binance returns per request.
+const maxTradeHistoryLimit = 1000
+
+// listAllTrades pages through the complete account trade history of a symbol.
+func listAllTrades(client *binance.Client, symbol string) ([]*binance.TradeV3, error) {
+	var trades []*binance.TradeV3
+	var nextID int64
+
+	for {
+		page, err := client.NewListTradesService().
+			Symbol(symbol).
+			FromID(nextID).
+			Limit(maxTradeHistoryLimit).
+			Do(context.Background())
+		if err != nil {
+			return nil, err
+		}
+
+		for _, trade := range page {
+			trades = append(trades, trade)
+			nextID = trade.ID + 1
+		}
+
+		if len(page) < maxTradeHistoryLimit {
+			return trades, nil
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package binance
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	binance "github.com/adshao/go-binance/v2"
+	"github.com/eliquious/console"
+	"github.com/eliquious/mercator/indicators"
+	"github.com/eliquious/mercator/output"
+	"github.com/gookit/color"
+)
+
+// indicatorKlineLimit is the number of klines loaded for the indicators. The longest moving average needs 200 and the
+// EMAs need extra history to settle.
+const indicatorKlineLimit = 500
+
+// atrPeriod is the period of the average true range.
+const atrPeriod = 14
+
+// getCandles loads the latest candles of a symbol.
+func getCandles(client *binance.Client, store *marketStore, symbol, interval string, limit int) ([]ohlcv, error) {
+	if !isKlineInterval(interval) {
+		return nil, fmt.Errorf("invalid interval: %s (expected one of %s)", interval, strings.Join(klineIntervals, ", "))
+	}
+
+	klines, err := listKlines(client, store, symbol, interval, time.Time{}, time.Time{}, limit)
+	if err != nil {
+		return nil, err
+	}
+	return parseKlines(klines)
+}
+
+// getCandleSeries splits the candles into high, low, close and volume series.
+func getCandleSeries(candles []ohlcv) ([]float64, []float64, []float64, []float64) {
+	high := make([]float64, len(candles))
+	low := make([]float64, len(candles))
+	closes := make([]float64, len(candles))
+	volume := make([]float64, len(candles))
+	for index, candle := range candles {
+		high[index] = candle.high
+		low[index] = candle.low
+		closes[index] = candle.close
+		volume[index] = candle.volume
+	}
+	return high, low, closes, volume
+}
+
+// getATR returns the latest average true range of a symbol.
+func getATR(client *binance.Client, store *marketStore, symbol, interval string) (float64, error) {
+	candles, err := getCandles(client, store, symbol, interval, indicatorKlineLimit)
+	if err != nil {
+		return 0, err
+	}
+
+	high, low, closes, _ := getCandleSeries(candles)
+	atr := indicators.Last(indicators.ATR(high, low, closes, atrPeriod))
+	if math.IsNaN(atr) {
+		return 0, fmt.Errorf("not enough %s klines to calculate the ATR", interval)
+	}
+	return atr, nil
+}
+
+func addIndicatorsCommand(scope *console.Scope, client *binance.Client, symbols []binance.Symbol) {
+	var interval string
+	command := &console.Command{
+		Use:   "indicators",
+		Short: "Show technical indicators for a symbol",
+		Long: `Shows moving averages, momentum and volatility indicators calculated from the latest klines. VWAP is
+calculated from the candles since midnight UTC for intraday intervals.
+
+    indicators BTCUSDT --interval 1h
+		`,
+		ValidateArgs:     console.MinimumArgs(1),
+		EagerSuggestions: true,
+		Suggestions: func(env *console.Environment, args []string) []string {
+			return getSymbolList(symbols)
+		},
+		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
+			defer resetFlags(cmd)
+			if len(args) != 1 {
+				return errors.New("requires 1 symbol")
+			}
+
+			symbol := strings.ToUpper(args[0])
+			info, ok := getSymbolMap(symbols)[symbol]
+			if !ok {
+				return errors.New("unknown symbol: " + args[0])
+			}
+
+			candles, err := getCandles(client, getMarketStore(env.Configuration), symbol, interval, indicatorKlineLimit)
+			if err != nil {
+				return err
+			} else if len(candles) == 0 {
+				return errors.New("no klines found")
+			}
+			high, low, closes, volume := getCandleSeries(candles)
+			last := closes[len(closes)-1]
+
+			result := &indicatorsResult{Symbol: symbol, Interval: interval, Candles: len(candles), Close: last, info: info}
+			for _, period := range []int{20, 50, 200} {
+				result.add("Moving Averages", fmt.Sprintf("SMA(%d)", period), priceIndicator, indicators.Last(indicators.SMA(closes, period)))
+			}
+			for _, period := range []int{12, 26} {
+				result.add("Moving Averages", fmt.Sprintf("EMA(%d)", period), priceIndicator, indicators.Last(indicators.EMA(closes, period)))
+			}
+
+			result.add("Momentum", "RSI(14)", rsiIndicator, indicators.Last(indicators.RSI(closes, 14)))
+			macd, signal, histogram := indicators.MACD(closes, 12, 26, 9)
+			result.add("Momentum", "MACD(12,26,9)", valueIndicator, indicators.Last(macd))
+			result.add("Momentum", "Signal", valueIndicator, indicators.Last(signal))
+			result.add("Momentum", "Histogram", changeIndicator, indicators.Last(histogram))
+
+			upper, middle, lower := indicators.Bollinger(closes, 20, 2)
+			result.add("Volatility", "Bollinger Upper(20,2)", priceIndicator, indicators.Last(upper))
+			result.add("Volatility", "Bollinger Middle(20,2)", priceIndicator, indicators.Last(middle))
+			result.add("Volatility", "Bollinger Lower(20,2)", priceIndicator, indicators.Last(lower))
+			result.add("Volatility", fmt.Sprintf("ATR(%d)", atrPeriod), rangeIndicator, indicators.Last(indicators.ATR(high, low, closes, atrPeriod)))
+
+			session := getSessionStart(candles, interval)
+			vwap := indicators.Last(indicators.VWAP(high[session:], low[session:], closes[session:], volume[session:]))
+			result.add("Volume", "VWAP", priceIndicator, vwap)
+			return output.Render(env, cmd, result)
+		},
+	}
+	command.Flags().StringVar(&interval, "interval", "1h", "Candle interval")
+	command.Flags().Lookup("interval").Annotations = map[string][]string{console.Suggestions: klineIntervals}
+	output.AddFlag(command)
+	scope.AddCommand(command)
+}
+
+// getSessionStart returns the index of the first candle of the current UTC day for intraday intervals. Longer
+// intervals use all the candles.
+func getSessionStart(candles []ohlcv, interval string) int {
+	if !strings.HasSuffix(interval, "m") && !strings.HasSuffix(interval, "h") {
+		return 0
+	}
+
+	midnight := time.Now().UTC().Truncate(24 * time.Hour)
+	for index, candle := range candles {
+		if !candle.openTime.Before(midnight) {
+			return index
+		}
+	}
+	return 0
+}
+
+// printIndicator prints a price indicator with the distance of the close from it.
+func printIndicator(w io.Writer, info binance.Symbol, name string, value, close float64) {
+	if math.IsNaN(value) {
+		fmt.Fprintf(w, "- %s: -\n", color.LightYellow.Render(name))
+		return
+	}
+
+	distance := (close - value) / value * 100
+	fmt.Fprintf(w, "- %s: %s (close %s)\n", color.LightYellow.Render(name), formatQuotePrice(info, value), formatChange(fmt.Sprint(distance), fmt.Sprintf("%+0.2f%%", distance)))
+}
+
+func formatIndicatorValue(info binance.Symbol, value float64) string {
+	if math.IsNaN(value) {
+		return "-"
+	}
+	return formatQuotePrice(info, value)
+}
+
+// formatRSI colors overbought values red and oversold values green.
+func formatRSI(rsi float64) string {
+	switch {
+	case math.IsNaN(rsi):
+		return "-"
+	case rsi >= 70:
+		return color.Red.Render(fmt.Sprintf("%0.2f (overbought)", rsi))
+	case rsi <= 30:
+		return color.Green.Render(fmt.Sprintf("%0.2f (oversold)", rsi))
+	default:
+		return fmt.Sprintf("%0.2f", rsi)
+	}
+}
@@ -0,0 +1,306 @@
+package binance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	binance "github.com/adshao/go-binance/v2"
+	"github.com/eliquious/console"
+	"github.com/eliquious/mercator/output"
+	"github.com/gookit/color"
+)
+
+// maxKlineLimit is the maximum number of klines Binance returns per request.
+const maxKlineLimit = 1000
+
+// defaultTerminalWidth and defaultTerminalHeight are used when the terminal size is unknown.
+const (
+	defaultTerminalWidth  = 80
+	defaultTerminalHeight = 24
+)
+
+// klineIntervals are the intervals supported by Binance.
+var klineIntervals = []string{"1m", "3m", "5m", "15m", "30m", "1h", "2h", "4h", "6h", "8h", "12h", "1d", "3d", "1w", "1M"}
+
+// ohlcv is a parsed kline.
+type ohlcv struct {
+	openTime  time.Time
+	closeTime time.Time
+	open      float64
+	high      float64
+	low       float64
+	close     float64
+	volume    float64
+}
+
+func parseKline(kline *binance.Kline) (ohlcv, error) {
+	var candle ohlcv
+	var err error
+	candle.openTime = time.Unix(0, kline.OpenTime*int64(time.Millisecond))
+	candle.closeTime = time.Unix(0, kline.CloseTime*int64(time.Millisecond))
+	if candle.open, err = strconv.ParseFloat(kline.Open, 64); err != nil {
+		return candle, fmt.Errorf("invalid open price: %s", kline.Open)
+	}
+	if candle.high, err = strconv.ParseFloat(kline.High, 64); err != nil {
+		return candle, fmt.Errorf("invalid high price: %s", kline.High)
+	}
+	if candle.low, err = strconv.ParseFloat(kline.Low, 64); err != nil {
+		return candle, fmt.Errorf("invalid low price: %s", kline.Low)
+	}
+	if candle.close, err = strconv.ParseFloat(kline.Close, 64); err != nil {
+		return candle, fmt.Errorf("invalid close price: %s", kline.Close)
+	}
+	if candle.volume, err = strconv.ParseFloat(kline.Volume, 64); err != nil {
+		return candle, fmt.Errorf("invalid volume: %s", kline.Volume)
+	}
+	return candle, nil
+}
+
+func parseKlines(klines []*binance.Kline) ([]ohlcv, error) {
+	candles := make([]ohlcv, 0, len(klines))
+	for _, kline := range klines {
+		candle, err := parseKline(kline)
+		if err != nil {
+			return nil, err
+		}
+		candles = append(candles, candle)
+	}
+	return candles, nil
+}
+
+// fetchKlines downloads the klines between two times. Without a start time the latest klines up to the limit are
+// returned. With a start time the results are paged until the end time.
+func fetchKlines(client *binance.Client, symbol, interval string, start, end time.Time, limit int) ([]*binance.Kline, error) {
+	if start.IsZero() {
+		exchange := client.NewKlinesService().Symbol(symbol).Interval(interval).Limit(limit)
+		if !end.IsZero() {
+			exchange = exchange.EndTime(toMillis(end) - 1)
+		}
+		return exchange.Do(context.Background())
+	}
+
+	var klines []*binance.Kline
+	cursor := toMillis(start)
+	for {
+		exchange := client.NewKlinesService().Symbol(symbol).Interval(interval).StartTime(cursor).Limit(maxKlineLimit)
+		if !end.IsZero() {
+			exchange = exchange.EndTime(toMillis(end) - 1)
+		}
+
+		page, err := exchange.Do(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		klines = append(klines, page...)
+
+		if len(page) < maxKlineLimit {
+			return klines, nil
+		}
+		cursor = page[len(page)-1].CloseTime + 1
+	}
+}
+
+// isKlineInterval checks if the interval is supported by Binance.
+func isKlineInterval(interval string) bool {
+	for _, valid := range klineIntervals {
+		if interval == valid {
+			return true
+		}
+	}
+	return false
+}
+
+func addKlinesCommand(scope *console.Scope, client *binance.Client, symbols []binance.Symbol) {
+	var interval, from, to string
+	var limit int
+	var chart bool
+	command := &console.Command{
+		Use:   "klines",
+		Short: "Show the candlestick history of a symbol",
+		Long: `Shows the OHLCV candles of a symbol in the output format or, with --chart, as a candlestick chart sized to
+the terminal. Without --from the latest candles up to --limit are shown. Dates are in the local timezone and the end
+date is inclusive.
+
+    klines BTCUSDT --interval 4h --chart
+    klines BTCUSDT --interval 1d --from 2020-01-01 --to 2020-12-31 --output csv
+		`,
+		ValidateArgs:     console.MinimumArgs(1),
+		EagerSuggestions: true,
+		Suggestions: func(env *console.Environment, args []string) []string {
+			return getSymbolList(symbols)
+		},
+		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
+			defer resetFlags(cmd)
+			if len(args) != 1 {
+				return errors.New("requires 1 symbol")
+			}
+
+			symbol := strings.ToUpper(args[0])
+			info, ok := getSymbolMap(symbols)[symbol]
+			if !ok {
+				return errors.New("unknown symbol: " + args[0])
+			}
+			if !isKlineInterval(interval) {
+				return fmt.Errorf("invalid interval: %s (expected one of %s)", interval, strings.Join(klineIntervals, ", "))
+			}
+			if limit <= 0 || limit > maxKlineLimit {
+				return fmt.Errorf("limit must be between 1 and %d", maxKlineLimit)
+			}
+			if chart && !output.IsTable(env, cmd) {
+				return errors.New("--chart only supports the table output")
+			}
+
+			var start, end time.Time
+			var err error
+			if cmd.Flags().Changed("from") {
+				if start, err = parseDate(from); err != nil {
+					return err
+				}
+			}
+			if cmd.Flags().Changed("to") {
+				if end, err = parseDate(to); err != nil {
+					return err
+				}
+				end = end.AddDate(0, 0, 1)
+			}
+			if !start.IsZero() && !end.IsZero() && !start.Before(end) {
+				return errors.New("from date must be before to date")
+			}
+
+			klines, err := listKlines(client, getMarketStore(env.Configuration), symbol, interval, start, end, limit)
+			if err != nil {
+				return err
+			}
+
+			if chart {
+				if len(klines) == 0 {
+					fmt.Println("No klines found")
+					return nil
+				}
+				candles, err := parseKlines(klines)
+				if err != nil {
+					return err
+				}
+				width, height := getTerminalSize()
+				fmt.Print(renderCandlestickChart(info, interval, candles, width, height))
+				return nil
+			}
+
+			result, err := newKlinesResult(info, interval, klines)
+			if err != nil {
+				return err
+			}
+			return output.Render(env, cmd, result)
+		},
+	}
+	command.Flags().StringVar(&interval, "interval", "1d", "Candle interval")
+	command.Flags().StringVar(&from, "from", "", "Start date (YYYY-MM-DD)")
+	command.Flags().StringVar(&to, "to", "", "End date (YYYY-MM-DD)")
+	command.Flags().IntVar(&limit, "limit", 100, "Number of candles when no start date is given")
+	command.Flags().BoolVar(&chart, "chart", false, "Draw a candlestick chart")
+	command.Flags().Lookup("interval").Annotations = map[string][]string{console.Suggestions: klineIntervals}
+	output.AddFlag(command)
+	scope.AddCommand(command)
+}
+
+// getKlineTimeFormat returns the time format for the candle interval.
+func getKlineTimeFormat(interval string) string {
+	if strings.HasSuffix(interval, "m") || strings.HasSuffix(interval, "h") {
+		return "2006-01-02 15:04"
+	}
+	return dateFormat
+}
+
+// renderCandlestickChart draws one column per candle with the price axis on the left. Only the latest candles that
+// fit in the width are drawn.
+func renderCandlestickChart(info binance.Symbol, interval string, candles []ohlcv, width, height int) string {
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, candle := range candles {
+		low = math.Min(low, candle.low)
+		high = math.Max(high, candle.high)
+	}
+
+	// the price labels are as wide as the largest price
+	labelWidth := len(formatQuotePrice(info, high))
+	chartWidth := width - labelWidth - 3
+	if chartWidth < 1 {
+		chartWidth = 1
+	}
+	if len(candles) > chartWidth {
+		candles = candles[len(candles)-chartWidth:]
+		low, high = math.Inf(1), math.Inf(-1)
+		for _, candle := range candles {
+			low = math.Min(low, candle.low)
+			high = math.Max(high, candle.high)
+		}
+	}
+
+	// leave room for the title, time axis and prompt
+	rows := height - 6
+	if rows < 5 {
+		rows = 5
+	}
+	if high == low {
+		high += high * 0.001
+		low -= low * 0.001
+	}
+	step := (high - low) / float64(rows)
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "\n%s %s\n", color.LightWhite.Render(info.Symbol), interval)
+	for row := 0; row < rows; row++ {
+		top := high - float64(row)*step
+		bottom := top - step
+
+		label := strings.Repeat(" ", labelWidth)
+		if row%4 == 0 || row == rows-1 {
+			label = fmt.Sprintf("%*s", labelWidth, formatQuotePrice(info, (top+bottom)/2))
+		}
+		builder.WriteString(label)
+		builder.WriteString(" ┤")
+
+		for _, candle := range candles {
+			bodyTop := math.Max(candle.open, candle.close)
+			bodyBottom := math.Min(candle.open, candle.close)
+
+			cell := " "
+			if bodyTop >= bottom && bodyBottom <= top {
+				cell = "┃"
+			} else if candle.high >= bottom && candle.low <= top {
+				cell = "│"
+			}
+
+			if cell != " " {
+				if candle.close < candle.open {
+					cell = color.Red.Render(cell)
+				} else {
+					cell = color.Green.Render(cell)
+				}
+			}
+			builder.WriteString(cell)
+		}
+		builder.WriteString("\n")
+	}
+
+	// time axis with the first and last candle
+	timeFormat := getKlineTimeFormat(interval)
+	first := candles[0].openTime.Local().Format(timeFormat)
+	last := candles[len(candles)-1].openTime.Local().Format(timeFormat)
+	builder.WriteString(strings.Repeat(" ", labelWidth))
+	builder.WriteString(" └")
+	builder.WriteString(strings.Repeat("─", len(candles)))
+	builder.WriteString("\n")
+	builder.WriteString(strings.Repeat(" ", labelWidth+2))
+	builder.WriteString(first)
+	if gap := len(candles) - len(first) - len(last); gap > 0 {
+		builder.WriteString(strings.Repeat(" ", gap))
+		builder.WriteString(last)
+	}
+	builder.WriteString("\n")
+	return builder.String()
+}
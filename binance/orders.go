@@ -0,0 +1,411 @@
+package binance
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	binance "github.com/adshao/go-binance/v2"
+	"github.com/eliquious/console"
+	"github.com/eliquious/mercator/output"
+	"github.com/gookit/color"
+)
+
+func addLimitOrderCommands(scope *console.Scope, traders *traders, symbols []binance.Symbol) {
+	scope.AddCommand(newLimitOrderCommand("limit-buy", "Place a limit buy order", binance.SideTypeBuy, traders, symbols))
+	scope.AddCommand(newLimitOrderCommand("limit-sell", "Place a limit sell order", binance.SideTypeSell, traders, symbols))
+}
+
+func newLimitOrderCommand(use, short string, side binance.SideType, traders *traders, symbols []binance.Symbol) *console.Command {
+	var inv, quantity, price float64
+	command := &console.Command{
+		Use:   use,
+		Short: short,
+		Long: `The order quantity is either given directly in the base asset or calculated from an investment amount in
+the quote asset. Quantities calculated from the investment are rounded down to the symbol's lot size.
+
+    ` + use + ` BTCUSDT --qty 0.05 --price 30000
+    ` + use + ` BTCUSDT --inv 500 --price 30000
+		`,
+		ValidateArgs:  console.MinimumArgs(1),
+		RequiredFlags: []string{"price"},
+		Suggestions: func(env *console.Environment, args []string) []string {
+			return getSymbolList(symbols)
+		},
+		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("requires 1 symbol")
+			}
+
+			if !cmd.Flags().Changed("price") {
+				return errors.New("price is required")
+			} else if price <= 0 {
+				return errors.New("price must be positive")
+			}
+
+			if cmd.Flags().Changed("qty") == cmd.Flags().Changed("inv") {
+				return errors.New("either quantity or investment amount is required")
+			}
+
+			info, err := getSymbolInfo(symbols, strings.ToUpper(args[0]))
+			if err != nil {
+				return err
+			}
+
+			filters, err := getSymbolFilters(info)
+			if err != nil {
+				return err
+			}
+
+			orderQuantity := quantity
+			if cmd.Flags().Changed("inv") {
+				if inv <= 0 {
+					return errors.New("investment amount must be positive")
+				}
+				orderQuantity = filters.roundQuantity(inv / price)
+			}
+
+			if err := filters.validateOrder(price, orderQuantity); err != nil {
+				return err
+			}
+
+			t, err := traders.get(env, cmd)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println()
+			printPaperNotice(t)
+			fmt.Printf("%s: %s\n", color.LightGreen.Render("Symbol"), info.Symbol)
+			fmt.Printf("%s:   %s\n", color.LightGreen.Render("Side"), formatSide(side))
+			fmt.Printf("%s:  %s %s\n", color.LightGreen.Render("Price"), filters.formatPrice(price), color.LightBlue.Render(info.QuoteAsset))
+			fmt.Printf("%s:    %s %s\n", color.LightGreen.Render("Qty"), filters.formatQuantity(orderQuantity), color.LightBlue.Render(info.BaseAsset))
+			fmt.Printf("%s:  %s %s\n\n", color.LightGreen.Render("Total"), formatQuotePrice(info, price*orderQuantity), color.LightBlue.Render(info.QuoteAsset))
+
+			if err := confirm(env, "Place order?"); err != nil {
+				return fmt.Errorf("order cancelled: %s", err)
+			}
+
+			order, err := t.createLimitOrder(info, side, filters.formatQuantity(orderQuantity), filters.formatPrice(price))
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("\n%s:  %d\n", color.LightGreen.Render("Order ID"), order.OrderID)
+			fmt.Printf("%s:    %s\n", color.LightGreen.Render("Status"), order.Status)
+			fmt.Printf("%s:  %s\n", color.LightGreen.Render("Executed"), order.ExecutedQuantity)
+			fmt.Printf("%s:      %s\n", color.LightGreen.Render("Time"), time.Unix(0, order.TransactTime*1e6).Local().Format("2006-01-02T15:04:05"))
+			return nil
+		},
+	}
+	command.Flags().Float64VarP(&quantity, "qty", "q", 0, "Order quantity in the base asset")
+	command.Flags().Float64VarP(&inv, "inv", "i", 0, "Investment amount in the quote asset")
+	command.Flags().Float64VarP(&price, "price", "p", 0, "Limit price")
+	command.Flags().Bool("dry-run", false, "Send the order to the paper trading engine")
+	return command
+}
+
+// printPaperNotice prints a warning when orders go to the paper trading engine.
+func printPaperNotice(t trader) {
+	if isPaper(t) {
+		fmt.Println(color.Warn.Render("PAPER TRADING"))
+	}
+}
+
+func formatSide(side binance.SideType) string {
+	if side == binance.SideTypeBuy {
+		return color.Green.Render(string(side))
+	}
+	return color.Red.Render(string(side))
+}
+
+func addOrderManagementCommands(scope *console.Scope, traders *traders, symbols []binance.Symbol) {
+	symbolSuggestions := func(env *console.Environment, args []string) []string {
+		if contains(args, "--symbol") && len(args) > 2 {
+			return getSymbolList(symbols)
+		}
+		return []string{}
+	}
+
+	var openSymbol string
+	openOrdersCommand := &console.Command{
+		Use:         "open-orders",
+		Short:       "List open orders",
+		Suggestions: symbolSuggestions,
+		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
+			t, err := traders.get(env, cmd)
+			if err != nil {
+				return err
+			}
+
+			// filter by symbol if set
+			var symbol string
+			if cmd.Flags().Changed("symbol") {
+				symbol = strings.ToUpper(openSymbol)
+			}
+
+			orders, err := t.listOpenOrders(symbol)
+			if err != nil {
+				return err
+			}
+
+			result := newOrdersResult(isPaper(t), orders)
+			result.empty = "No open orders"
+			return output.Render(env, cmd, result)
+		},
+	}
+	openOrdersCommand.Flags().StringVar(&openSymbol, "symbol", "", "Filter orders by this symbol")
+	openOrdersCommand.Flags().Bool("dry-run", false, "Use the paper trading engine")
+	output.AddFlag(openOrdersCommand)
+	scope.AddCommand(openOrdersCommand)
+
+	var statusSymbol string
+	var statusID int64
+	orderStatusCommand := &console.Command{
+		Use:           "order-status",
+		Short:         "Get the status of an order",
+		RequiredFlags: []string{"symbol", "id"},
+		Suggestions:   symbolSuggestions,
+		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
+			if !cmd.Flags().Changed("symbol") || !cmd.Flags().Changed("id") {
+				return errors.New("symbol and order id are required")
+			}
+
+			t, err := traders.get(env, cmd)
+			if err != nil {
+				return err
+			}
+
+			order, err := t.getOrder(strings.ToUpper(statusSymbol), statusID)
+			if err != nil {
+				return err
+			}
+
+			return output.Render(env, cmd, newOrdersResult(isPaper(t), []*binance.Order{order}))
+		},
+	}
+	orderStatusCommand.Flags().StringVar(&statusSymbol, "symbol", "", "Symbol of the order")
+	orderStatusCommand.Flags().Int64Var(&statusID, "id", 0, "Order ID")
+	orderStatusCommand.Flags().Bool("dry-run", false, "Use the paper trading engine")
+	output.AddFlag(orderStatusCommand)
+	scope.AddCommand(orderStatusCommand)
+
+	var cancelSymbol string
+	var cancelID int64
+	cancelOrderCommand := &console.Command{
+		Use:           "cancel-order",
+		Short:         "Cancel an open order",
+		RequiredFlags: []string{"symbol", "id"},
+		Suggestions:   symbolSuggestions,
+		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
+			if !cmd.Flags().Changed("symbol") || !cmd.Flags().Changed("id") {
+				return errors.New("symbol and order id are required")
+			}
+
+			t, err := traders.get(env, cmd)
+			if err != nil {
+				return err
+			}
+
+			resp, err := t.cancelOrder(strings.ToUpper(cancelSymbol), cancelID)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("%s: %d %s\n", color.LightGreen.Render("Cancelled"), resp.OrderID, resp.Status)
+			return nil
+		},
+	}
+	cancelOrderCommand.Flags().StringVar(&cancelSymbol, "symbol", "", "Symbol of the order")
+	cancelOrderCommand.Flags().Int64Var(&cancelID, "id", 0, "Order ID")
+	cancelOrderCommand.Flags().Bool("dry-run", false, "Use the paper trading engine")
+	scope.AddCommand(cancelOrderCommand)
+
+	var cancelAllSymbol string
+	cancelAllCommand := &console.Command{
+		Use:           "cancel-all",
+		Short:         "Cancel all open orders for a symbol",
+		RequiredFlags: []string{"symbol"},
+		Suggestions:   symbolSuggestions,
+		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
+			if !cmd.Flags().Changed("symbol") {
+				return errors.New("symbol is required")
+			}
+			symbol := strings.ToUpper(cancelAllSymbol)
+
+			t, err := traders.get(env, cmd)
+			if err != nil {
+				return err
+			}
+
+			orders, err := t.listOpenOrders(symbol)
+			if err != nil {
+				return err
+			}
+
+			if len(orders) == 0 {
+				fmt.Println("No open orders")
+				return nil
+			}
+			newOrdersResult(isPaper(t), orders).Print(os.Stdout)
+
+			if err := confirm(env, fmt.Sprintf("Cancel %d open order(s) for %s?", len(orders), symbol)); err != nil {
+				return fmt.Errorf("cancel aborted: %s", err)
+			}
+
+			resp, err := t.cancelOpenOrders(symbol)
+			if err != nil {
+				return err
+			}
+
+			for _, order := range resp.Orders {
+				fmt.Printf("%s: %d %s\n", color.LightGreen.Render("Cancelled"), order.OrderID, order.Status)
+			}
+			for _, order := range resp.OCOOrders {
+				fmt.Printf("%s: OCO %d %s\n", color.LightGreen.Render("Cancelled"), order.OrderListID, order.ListOrderStatus)
+			}
+			return nil
+		},
+	}
+	cancelAllCommand.Flags().StringVar(&cancelAllSymbol, "symbol", "", "Cancel orders for this symbol")
+	cancelAllCommand.Flags().Bool("dry-run", false, "Use the paper trading engine")
+	scope.AddCommand(cancelAllCommand)
+}
+
+// orderPollInterval is how often an order is checked while waiting for it to fill.
+const orderPollInterval = 5 * time.Second
+
+// placeRiskOrders turns a risk plan into orders. A limit buy is placed at the entry price and once it has filled an
+// OCO sell is placed with a take profit at the target price and a stop-limit at the stop price.
+func placeRiskOrders(env *console.Environment, t trader, info binance.Symbol, inv, entry, stop, stopLimit, target float64) error {
+	if !info.OcoAllowed {
+		return fmt.Errorf("OCO orders are not allowed for %s", info.Symbol)
+	}
+	if stopLimit > stop {
+		return errors.New("stop limit price must not be above the stop price")
+	}
+
+	filters, err := getSymbolFilters(info)
+	if err != nil {
+		return err
+	}
+
+	entry = filters.roundPrice(entry)
+	stop = filters.roundPrice(stop)
+	stopLimit = filters.roundPrice(stopLimit)
+	target = filters.roundPrice(target)
+	quantity := filters.roundQuantity(inv / entry)
+
+	if err := filters.validateOrder(entry, quantity); err != nil {
+		return err
+	}
+
+	// the exit is sold from what the entry leaves after commission, so it is checked with the largest commission that
+	// can be taken from the base asset before the entry is placed
+	account, err := t.getAccount()
+	if err != nil {
+		return err
+	}
+	rate := float64(maxInt64(account.MakerCommission, account.TakerCommission)) / 10000
+	minExitQuantity := filters.roundQuantity(quantity * (1 - rate))
+	for _, price := range []float64{stop, stopLimit, target} {
+		if err := filters.validateOrder(price, minExitQuantity); err != nil {
+			return fmt.Errorf("exit order after commission: %v", err)
+		}
+	}
+
+	fmt.Println()
+	printPaperNotice(t)
+	fmt.Printf("%s:       %s %s at %s\n", color.LightGreen.Render("Entry"), filters.formatQuantity(quantity), color.LightBlue.Render(info.BaseAsset), filters.formatPrice(entry))
+	fmt.Printf("%s: %s\n", color.LightGreen.Render("Take Profit"), filters.formatPrice(target))
+	fmt.Printf("%s:        %s\n", color.LightGreen.Render("Stop"), filters.formatPrice(stop))
+	fmt.Printf("%s:  %s\n\n", color.LightGreen.Render("Stop Limit"), filters.formatPrice(stopLimit))
+
+	if err := confirm(env, "Place entry order? The OCO exit is placed once it fills."); err != nil {
+		return fmt.Errorf("order cancelled: %s", err)
+	}
+
+	order, err := t.createLimitOrder(info, binance.SideTypeBuy, filters.formatQuantity(quantity), filters.formatPrice(entry))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s: %d %s\n", color.LightGreen.Render("Entry Order"), order.OrderID, order.Status)
+
+	if order.Status != binance.OrderStatusTypeFilled {
+		fmt.Println("Waiting for the entry order to fill. Press Ctrl-C to stop waiting.")
+		filled, err := waitForFill(t, info.Symbol, order.OrderID)
+		if err != nil {
+			return err
+		} else if !filled {
+			color.Warn.Printf("stopped waiting. the OCO exit has not been placed for order %d\n", order.OrderID)
+			return nil
+		}
+	}
+
+	// the buy commission may have been taken from the base asset
+	free, _, err := getTraderBalance(t, info.BaseAsset)
+	if err != nil {
+		return err
+	}
+	exitQuantity := filters.roundQuantity(math.Min(quantity, free))
+
+	oco, err := t.createOCO(info,
+		filters.formatQuantity(exitQuantity),
+		filters.formatPrice(target),
+		filters.formatPrice(stop),
+		filters.formatPrice(stopLimit),
+	)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s: %d %s\n", color.LightGreen.Render("OCO Order"), oco.OrderListID, oco.ListOrderStatus)
+	for _, report := range oco.OrderReports {
+		fmt.Printf("  %d %s %s at %s\n", report.OrderID, report.Type, report.Status, report.Price)
+	}
+	return nil
+}
+
+// waitForFill polls the order until it has filled. It returns false if the order is no longer open or if
+// waiting was interrupted.
+func waitForFill(t trader, symbol string, orderID int64) (bool, error) {
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	ticker := time.NewTicker(orderPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-interrupt:
+			return false, nil
+		case <-ticker.C:
+			order, err := t.getOrder(symbol, orderID)
+			if err != nil {
+				return false, err
+			}
+
+			switch order.Status {
+			case binance.OrderStatusTypeFilled:
+				return true, nil
+			case binance.OrderStatusTypeNew, binance.OrderStatusTypePartiallyFilled:
+				continue
+			default:
+				color.Warn.Printf("order %d is %s\n", orderID, order.Status)
+				return false, nil
+			}
+		}
+	}
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,694 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	binance "github.com/adshao/go-binance/v2"
+	"github.com/eliquious/console"
+	"github.com/gookit/color"
+)
+
+// paperBalance is a simulated asset balance.
+type paperBalance struct {
+	Free   float64 `json:"free"`
+	Locked float64 `json:"locked"`
+}
+
+// paperOrder is a simulated order. Both orders of an OCO share the same order list ID.
+type paperOrder struct {
+	binance.Order
+	OrderListID int64 `json:"orderListId"`
+}
+
+// paperState is the simulated account persisted to disk.
+type paperState struct {
+	Balances    map[string]*paperBalance `json:"balances"`
+	Orders      []*paperOrder            `json:"orders"`
+	Trades      []*binance.TradeV3       `json:"trades"`
+	NextOrderID int64                    `json:"nextOrderId"`
+	NextListID  int64                    `json:"nextListId"`
+	NextTradeID int64                    `json:"nextTradeId"`
+}
+
+// paperTrader is a local matching engine for paper trading. Marketable orders are filled against the order book when
+// they are placed. Resting orders are matched against the last price whenever the paper account is accessed. Orders
+// are always filled completely.
+type paperTrader struct {
+	client  *binance.Client
+	symbols map[string]binance.Symbol
+	path    string
+
+	mu    sync.Mutex
+	fee   float64
+	state paperState
+}
+
+func newPaperState() paperState {
+	return paperState{
+		Balances:    map[string]*paperBalance{},
+		NextOrderID: 1,
+		NextListID:  1,
+		NextTradeID: 1,
+	}
+}
+
+// loadPaperTrader loads the paper account from the file. A new account is created if the file does not exist.
+func loadPaperTrader(client *binance.Client, symbols []binance.Symbol, path string) (*paperTrader, error) {
+	if path == "" {
+		return nil, errors.New("binance.paper_file is not set")
+	}
+
+	t := &paperTrader{
+		client:  client,
+		symbols: getSymbolMap(symbols),
+		path:    path,
+		state:   newPaperState(),
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return t, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &t.state); err != nil {
+		return nil, fmt.Errorf("invalid paper trading file: %s", err)
+	}
+	if t.state.Balances == nil {
+		t.state.Balances = map[string]*paperBalance{}
+	}
+	return t, nil
+}
+
+func (t *paperTrader) setFee(fee float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.fee = fee
+}
+
+// save writes the paper account to disk.
+func (t *paperTrader) save() error {
+	if err := os.MkdirAll(filepath.Dir(t.path), 0700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(t.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(t.path, data, 0600)
+}
+
+// deposit adds funds to the paper account.
+func (t *paperTrader) deposit(asset string, amount float64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	balance := t.balance(asset)
+	balance.Free = roundAmount(balance.Free + amount)
+	return t.save()
+}
+
+// reset clears all balances, orders and trades from the paper account.
+func (t *paperTrader) reset() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.state = newPaperState()
+	return t.save()
+}
+
+func (t *paperTrader) balance(asset string) *paperBalance {
+	balance, ok := t.state.Balances[asset]
+	if !ok {
+		balance = &paperBalance{}
+		t.state.Balances[asset] = balance
+	}
+	return balance
+}
+
+func (t *paperTrader) createLimitOrder(info binance.Symbol, side binance.SideType, quantity, price string) (*binance.CreateOrderResponse, error) {
+	qty, err := strconv.ParseFloat(quantity, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quantity: %s", quantity)
+	}
+	limit, err := strconv.ParseFloat(price, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid price: %s", price)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.match(); err != nil {
+		return nil, err
+	}
+
+	bid, ask, err := t.getBestPrices(info.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.lock(info, side, qty, limit); err != nil {
+		return nil, err
+	}
+	order := t.newOrder(info.Symbol, side, binance.OrderTypeLimit, quantity, price, "")
+
+	// fill immediately if the order crosses the book
+	if side == binance.SideTypeBuy && ask > 0 && ask <= limit {
+		t.fill(order, ask)
+	} else if side == binance.SideTypeSell && bid > 0 && bid >= limit {
+		t.fill(order, bid)
+	}
+
+	if err := t.save(); err != nil {
+		return nil, err
+	}
+
+	return &binance.CreateOrderResponse{
+		Symbol:                   order.Symbol,
+		OrderID:                  order.OrderID,
+		ClientOrderID:            order.ClientOrderID,
+		TransactTime:             order.Time,
+		Price:                    order.Price,
+		OrigQuantity:             order.OrigQuantity,
+		ExecutedQuantity:         order.ExecutedQuantity,
+		CummulativeQuoteQuantity: order.CummulativeQuoteQuantity,
+		Status:                   order.Status,
+		TimeInForce:              order.TimeInForce,
+		Type:                     order.Type,
+		Side:                     order.Side,
+	}, nil
+}
+
+func (t *paperTrader) createOCO(info binance.Symbol, quantity, price, stopPrice, stopLimitPrice string) (*binance.CreateOCOResponse, error) {
+	qty, err := strconv.ParseFloat(quantity, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quantity: %s", quantity)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.match(); err != nil {
+		return nil, err
+	}
+
+	// both orders share the locked quantity
+	if err := t.lock(info, binance.SideTypeSell, qty, 0); err != nil {
+		return nil, err
+	}
+
+	listID := t.state.NextListID
+	t.state.NextListID++
+
+	stopOrder := t.newOrder(info.Symbol, binance.SideTypeSell, binance.OrderTypeStopLossLimit, quantity, stopLimitPrice, stopPrice)
+	stopOrder.OrderListID = listID
+	limitOrder := t.newOrder(info.Symbol, binance.SideTypeSell, binance.OrderTypeLimitMaker, quantity, price, "")
+	limitOrder.OrderListID = listID
+
+	if err := t.save(); err != nil {
+		return nil, err
+	}
+
+	resp := &binance.CreateOCOResponse{
+		OrderListID:       listID,
+		ContingencyType:   "OCO",
+		ListStatusType:    "EXEC_STARTED",
+		ListOrderStatus:   "EXECUTING",
+		ListClientOrderID: fmt.Sprintf("paper-list-%d", listID),
+		TransactionTime:   stopOrder.Time,
+		Symbol:            info.Symbol,
+	}
+	for _, order := range []*paperOrder{stopOrder, limitOrder} {
+		resp.Orders = append(resp.Orders, &binance.OCOOrder{
+			Symbol:        order.Symbol,
+			OrderID:       order.OrderID,
+			ClientOrderID: order.ClientOrderID,
+		})
+		resp.OrderReports = append(resp.OrderReports, &binance.OCOOrderReport{
+			Symbol:                   order.Symbol,
+			OrderID:                  order.OrderID,
+			OrderListID:              listID,
+			ClientOrderID:            order.ClientOrderID,
+			TransactionTime:          order.Time,
+			Price:                    order.Price,
+			OrigQuantity:             order.OrigQuantity,
+			ExecutedQuantity:         order.ExecutedQuantity,
+			CummulativeQuoteQuantity: order.CummulativeQuoteQuantity,
+			Status:                   order.Status,
+			TimeInForce:              order.TimeInForce,
+			Type:                     order.Type,
+			Side:                     order.Side,
+			StopPrice:                order.StopPrice,
+		})
+	}
+	return resp, nil
+}
+
+func (t *paperTrader) getOrder(symbol string, orderID int64) (*binance.Order, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.match(); err != nil {
+		return nil, err
+	}
+
+	order := t.findOrder(symbol, orderID)
+	if order == nil {
+		return nil, errors.New("order does not exist")
+	}
+	o := order.Order
+	return &o, nil
+}
+
+func (t *paperTrader) listOpenOrders(symbol string) ([]*binance.Order, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.match(); err != nil {
+		return nil, err
+	}
+
+	var orders []*binance.Order
+	for _, order := range t.state.Orders {
+		if order.Status == binance.OrderStatusTypeNew && (symbol == "" || order.Symbol == symbol) {
+			o := order.Order
+			orders = append(orders, &o)
+		}
+	}
+	return orders, nil
+}
+
+func (t *paperTrader) cancelOrder(symbol string, orderID int64) (*binance.CancelOrderResponse, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.match(); err != nil {
+		return nil, err
+	}
+
+	order := t.findOrder(symbol, orderID)
+	if order == nil {
+		return nil, errors.New("order does not exist")
+	} else if order.Status != binance.OrderStatusTypeNew {
+		return nil, fmt.Errorf("order is %s", order.Status)
+	}
+
+	t.cancel(order)
+	if err := t.save(); err != nil {
+		return nil, err
+	}
+	return newPaperCancelResponse(order), nil
+}
+
+func (t *paperTrader) cancelOpenOrders(symbol string) (*binance.CancelOpenOrdersResponse, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.match(); err != nil {
+		return nil, err
+	}
+
+	resp := &binance.CancelOpenOrdersResponse{}
+	for _, order := range t.state.Orders {
+		if order.Symbol != symbol || order.Status != binance.OrderStatusTypeNew {
+			continue
+		}
+
+		t.cancel(order)
+		if order.OrderListID > 0 {
+			resp.OCOOrders = append(resp.OCOOrders, &binance.CancelOCOResponse{
+				OrderListID:     order.OrderListID,
+				ContingencyType: "OCO",
+				ListStatusType:  "ALL_DONE",
+				ListOrderStatus: "ALL_DONE",
+				Symbol:          order.Symbol,
+			})
+		} else {
+			resp.Orders = append(resp.Orders, newPaperCancelResponse(order))
+		}
+	}
+
+	if err := t.save(); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (t *paperTrader) getAccount() (*binance.Account, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.match(); err != nil {
+		return nil, err
+	}
+
+	commission := int64(math.Round(t.fee * 10000))
+	account := &binance.Account{
+		MakerCommission: commission,
+		TakerCommission: commission,
+		CanTrade:        true,
+	}
+
+	assets := make([]string, 0, len(t.state.Balances))
+	for asset := range t.state.Balances {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+
+	for _, asset := range assets {
+		balance := t.state.Balances[asset]
+		account.Balances = append(account.Balances, binance.Balance{
+			Asset:  asset,
+			Free:   strconv.FormatFloat(balance.Free, 'f', 8, 64),
+			Locked: strconv.FormatFloat(balance.Locked, 'f', 8, 64),
+		})
+	}
+	return account, nil
+}
+
+func (t *paperTrader) listTrades(symbol string, limit int) ([]*binance.TradeV3, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.match(); err != nil {
+		return nil, err
+	}
+
+	var trades []*binance.TradeV3
+	for _, trade := range t.state.Trades {
+		if trade.Symbol == symbol {
+			trades = append(trades, trade)
+		}
+	}
+
+	// like the exchange, the limit returns the most recent trades
+	if limit > 0 && len(trades) > limit {
+		trades = trades[len(trades)-limit:]
+	}
+	return trades, nil
+}
+
+// lock moves the funds required for an order from free to locked. Buys lock the quote asset and sells lock the base
+// asset.
+func (t *paperTrader) lock(info binance.Symbol, side binance.SideType, quantity, price float64) error {
+	asset, amount := info.BaseAsset, quantity
+	if side == binance.SideTypeBuy {
+		asset, amount = info.QuoteAsset, quantity*price
+	}
+
+	balance := t.balance(asset)
+	if balance.Free < amount {
+		return fmt.Errorf("insufficient paper balance: %s %s free", formatAmount(balance.Free), asset)
+	}
+	balance.Free = roundAmount(balance.Free - amount)
+	balance.Locked = roundAmount(balance.Locked + amount)
+	return nil
+}
+
+func (t *paperTrader) newOrder(symbol string, side binance.SideType, orderType binance.OrderType, quantity, price, stopPrice string) *paperOrder {
+	now := toMillis(time.Now())
+	order := &paperOrder{
+		Order: binance.Order{
+			Symbol:                   symbol,
+			OrderID:                  t.state.NextOrderID,
+			ClientOrderID:            fmt.Sprintf("paper-%d", t.state.NextOrderID),
+			Price:                    price,
+			OrigQuantity:             quantity,
+			ExecutedQuantity:         "0",
+			CummulativeQuoteQuantity: "0",
+			Status:                   binance.OrderStatusTypeNew,
+			TimeInForce:              binance.TimeInForceTypeGTC,
+			Type:                     orderType,
+			Side:                     side,
+			StopPrice:                stopPrice,
+			Time:                     now,
+			UpdateTime:               now,
+			IsWorking:                true,
+		},
+	}
+	t.state.NextOrderID++
+	t.state.Orders = append(t.state.Orders, order)
+	return order
+}
+
+func (t *paperTrader) findOrder(symbol string, orderID int64) *paperOrder {
+	for _, order := range t.state.Orders {
+		if order.OrderID == orderID && order.Symbol == symbol {
+			return order
+		}
+	}
+	return nil
+}
+
+// fill executes the whole order at the given price and records the trade. Commissions are taken from the asset
+// received. Filling one order of an OCO cancels the other.
+func (t *paperTrader) fill(order *paperOrder, price float64) {
+	info := t.symbols[order.Symbol]
+	quantity, _ := strconv.ParseFloat(order.OrigQuantity, 64)
+	limit, _ := strconv.ParseFloat(order.Price, 64)
+
+	base := t.balance(info.BaseAsset)
+	quote := t.balance(info.QuoteAsset)
+	total := quantity * price
+
+	trade := &binance.TradeV3{
+		ID:            t.state.NextTradeID,
+		Symbol:        order.Symbol,
+		OrderID:       order.OrderID,
+		Price:         strconv.FormatFloat(price, 'f', -1, 64),
+		Quantity:      order.OrigQuantity,
+		QuoteQuantity: strconv.FormatFloat(total, 'f', -1, 64),
+		Time:          toMillis(time.Now()),
+		IsBuyer:       order.Side == binance.SideTypeBuy,
+		IsMaker:       price == limit,
+		IsBestMatch:   true,
+	}
+	t.state.NextTradeID++
+
+	if order.Side == binance.SideTypeBuy {
+		commission := quantity * t.fee
+
+		// the difference between the limit and fill price is returned
+		quote.Locked = roundAmount(quote.Locked - quantity*limit)
+		quote.Free = roundAmount(quote.Free + quantity*limit - total)
+		base.Free = roundAmount(base.Free + quantity - commission)
+
+		trade.Commission = strconv.FormatFloat(commission, 'f', -1, 64)
+		trade.CommissionAsset = info.BaseAsset
+	} else {
+		commission := total * t.fee
+
+		base.Locked = roundAmount(base.Locked - quantity)
+		quote.Free = roundAmount(quote.Free + total - commission)
+
+		trade.Commission = strconv.FormatFloat(commission, 'f', -1, 64)
+		trade.CommissionAsset = info.QuoteAsset
+	}
+	t.state.Trades = append(t.state.Trades, trade)
+
+	order.Status = binance.OrderStatusTypeFilled
+	order.ExecutedQuantity = order.OrigQuantity
+	order.CummulativeQuoteQuantity = trade.QuoteQuantity
+	order.UpdateTime = trade.Time
+	order.IsWorking = false
+
+	// the locked quantity of an OCO is shared so it has already been released
+	if order.OrderListID > 0 {
+		for _, other := range t.state.Orders {
+			if other.OrderListID == order.OrderListID && other.Status == binance.OrderStatusTypeNew {
+				other.Status = binance.OrderStatusTypeExpired
+				other.UpdateTime = trade.Time
+				other.IsWorking = false
+			}
+		}
+	}
+}
+
+// cancel cancels the order and releases the locked funds. Cancelling one order of an OCO cancels both.
+func (t *paperTrader) cancel(order *paperOrder) {
+	info := t.symbols[order.Symbol]
+	quantity, _ := strconv.ParseFloat(order.OrigQuantity, 64)
+	price, _ := strconv.ParseFloat(order.Price, 64)
+
+	asset, amount := info.BaseAsset, quantity
+	if order.Side == binance.SideTypeBuy {
+		asset, amount = info.QuoteAsset, quantity*price
+	}
+	balance := t.balance(asset)
+	balance.Locked = roundAmount(balance.Locked - amount)
+	balance.Free = roundAmount(balance.Free + amount)
+
+	now := toMillis(time.Now())
+	for _, other := range t.state.Orders {
+		if other == order || (order.OrderListID > 0 && other.OrderListID == order.OrderListID && other.Status == binance.OrderStatusTypeNew) {
+			other.Status = binance.OrderStatusTypeCanceled
+			other.UpdateTime = now
+			other.IsWorking = false
+		}
+	}
+}
+
+// match fills the open orders that have been crossed by the last price.
+func (t *paperTrader) match() error {
+	var open []*paperOrder
+	for _, order := range t.state.Orders {
+		if order.Status == binance.OrderStatusTypeNew {
+			open = append(open, order)
+		}
+	}
+	if len(open) == 0 {
+		return nil
+	}
+
+	prices, err := getCurrentPrices(t.client)
+	if err != nil {
+		return err
+	}
+
+	var filled bool
+	for _, order := range open {
+		// filling one side of an OCO expires the other
+		if order.Status != binance.OrderStatusTypeNew {
+			continue
+		}
+
+		last, err := strconv.ParseFloat(prices[order.Symbol], 64)
+		if err != nil {
+			continue
+		}
+		price, _ := strconv.ParseFloat(order.Price, 64)
+		stopPrice, _ := strconv.ParseFloat(order.StopPrice, 64)
+
+		buy := order.Side == binance.SideTypeBuy
+		switch order.Type {
+		case binance.OrderTypeLimit, binance.OrderTypeLimitMaker:
+			if (buy && last <= price) || (!buy && last >= price) {
+				t.fill(order, price)
+				filled = true
+			}
+		case binance.OrderTypeStopLossLimit:
+			if (buy && last >= stopPrice) || (!buy && last <= stopPrice) {
+				t.fill(order, price)
+				filled = true
+			}
+		}
+	}
+
+	if !filled {
+		return nil
+	}
+	return t.save()
+}
+
+// getBestPrices returns the best bid and ask from the order book.
+func (t *paperTrader) getBestPrices(symbol string) (float64, float64, error) {
+	book, err := t.client.NewDepthService().Symbol(symbol).Limit(5).Do(context.Background())
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var bid, ask float64
+	if len(book.Bids) > 0 {
+		bid, _ = strconv.ParseFloat(book.Bids[0].Price, 64)
+	}
+	if len(book.Asks) > 0 {
+		ask, _ = strconv.ParseFloat(book.Asks[0].Price, 64)
+	}
+	return bid, ask, nil
+}
+
+func newPaperCancelResponse(order *paperOrder) *binance.CancelOrderResponse {
+	return &binance.CancelOrderResponse{
+		Symbol:                   order.Symbol,
+		OrigClientOrderID:        order.ClientOrderID,
+		OrderID:                  order.OrderID,
+		OrderListID:              order.OrderListID,
+		ClientOrderID:            order.ClientOrderID,
+		TransactTime:             order.UpdateTime,
+		Price:                    order.Price,
+		OrigQuantity:             order.OrigQuantity,
+		ExecutedQuantity:         order.ExecutedQuantity,
+		CummulativeQuoteQuantity: order.CummulativeQuoteQuantity,
+		Status:                   order.Status,
+		TimeInForce:              order.TimeInForce,
+		Type:                     order.Type,
+		Side:                     order.Side,
+	}
+}
+
+// roundAmount removes floating point noise from balances.
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*1e8) / 1e8
+}
+
+func addPaperCommands(scope *console.Scope, traders *traders, symbols []binance.Symbol) {
+	var asset string
+	var amount float64
+	depositCommand := &console.Command{
+		Use:           "paper-deposit",
+		Short:         "Add funds to the paper trading account",
+		RequiredFlags: []string{"asset", "amount"},
+		Suggestions: func(env *console.Environment, args []string) []string {
+			if contains(args, "--asset") && len(args) > 2 {
+				return getBaseAssetList(symbols)
+			}
+			return []string{}
+		},
+		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
+			if !cmd.Flags().Changed("asset") {
+				return errors.New("asset is required")
+			}
+			if !cmd.Flags().Changed("amount") {
+				return errors.New("amount is required")
+			} else if amount <= 0 {
+				return errors.New("amount must be positive")
+			}
+
+			paper, err := traders.getPaper(env)
+			if err != nil {
+				return err
+			}
+
+			if err := paper.deposit(strings.ToUpper(asset), amount); err != nil {
+				return err
+			}
+			fmt.Printf("%s: %s %s\n", color.LightGreen.Render("Deposited"), formatAmount(amount), color.LightBlue.Render(strings.ToUpper(asset)))
+			return nil
+		},
+	}
+	depositCommand.Flags().StringVar(&asset, "asset", "", "Asset to deposit")
+	depositCommand.Flags().Float64Var(&amount, "amount", 0, "Amount to deposit")
+	scope.AddCommand(depositCommand)
+
+	resetCommand := &console.Command{
+		Use:   "paper-reset",
+		Short: "Clear all balances, orders and trades from the paper trading account",
+		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
+			paper, err := traders.getPaper(env)
+			if err != nil {
+				return err
+			}
+
+			if err := confirm(env, "Reset the paper trading account?"); err != nil {
+				return fmt.Errorf("reset cancelled: %s", err)
+			}
+			return paper.reset()
+		},
+	}
+	scope.AddCommand(resetCommand)
+}
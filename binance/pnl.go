@@ -0,0 +1,347 @@
+package binance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	binance "github.com/adshao/go-binance/v2"
+	"github.com/eliquious/console"
+	"github.com/eliquious/mercator/costbasis"
+	"github.com/eliquious/mercator/output"
+)
+
+// historicalPriceInterval is the kline interval used to value assets at the time of a trade.
+const historicalPriceInterval = "1h"
+
+// historicalPrices looks up the close of the kline containing a time. Klines are loaded once per symbol for the whole
+// time range.
+type historicalPrices struct {
+	client *binance.Client
+	store  *marketStore
+	start  time.Time
+	end    time.Time
+	klines map[string][]*binance.Kline
+}
+
+func newHistoricalPrices(client *binance.Client, store *marketStore, start, end time.Time) *historicalPrices {
+	return &historicalPrices{
+		client: client,
+		store:  store,
+		start:  start.Add(-time.Hour),
+		end:    end.Add(time.Hour),
+		klines: make(map[string][]*binance.Kline),
+	}
+}
+
+func (h *historicalPrices) priceAt(symbol string, at time.Time) (float64, error) {
+	klines, ok := h.klines[symbol]
+	if !ok {
+		var err error
+		if klines, err = listKlines(h.client, h.store, symbol, historicalPriceInterval, h.start, h.end, maxKlineLimit); err != nil {
+			return 0, err
+		}
+		h.klines[symbol] = klines
+	}
+
+	millis := toMillis(at)
+	index := sort.Search(len(klines), func(i int) bool {
+		return klines[i].OpenTime > millis
+	})
+	if index == 0 {
+		return 0, fmt.Errorf("no %s price at %s", symbol, at.Format(time.RFC3339))
+	}
+	return strconv.ParseFloat(klines[index-1].Close, 64)
+}
+
+// assetValuer values assets in the reporting currency at a point in time.
+type assetValuer struct {
+	graph  *conversionGraph
+	prices *historicalPrices
+	quote  string
+}
+
+func (v *assetValuer) value(asset string, amount float64, at time.Time) (float64, error) {
+	if asset == v.quote || amount == 0 {
+		return amount, nil
+	}
+
+	route, ok := v.graph.findRoute(asset, v.quote)
+	if !ok {
+		return 0, fmt.Errorf("no market to value %s in %s", asset, v.quote)
+	}
+
+	rate := 1.0
+	for _, step := range route {
+		price, err := v.prices.priceAt(step.symbol, at)
+		if err != nil {
+			return 0, err
+		}
+		if step.inverse {
+			rate /= price
+		} else {
+			rate *= price
+		}
+	}
+	return amount * rate, nil
+}
+
+// tradeLedger records trades in a cost basis ledger. The reporting currency is not tracked since its cost is itself.
+type tradeLedger struct {
+	*costbasis.Ledger
+	valuer *assetValuer
+}
+
+func (l *tradeLedger) acquire(asset string, quantity, cost float64, at time.Time) {
+	if asset != l.valuer.quote {
+		l.Acquire(asset, quantity, cost, at)
+	}
+}
+
+func (l *tradeLedger) dispose(asset string, quantity, proceeds float64, at time.Time) {
+	if asset != l.valuer.quote {
+		l.Dispose(asset, quantity, proceeds, at)
+	}
+}
+
+// record adds a trade to the ledger. A trade disposes of one asset and acquires the other at the value of the quote
+// amount. Commissions taken from the received asset reduce the quantity received. Commissions paid in another asset,
+// such as BNB, are a disposal of that asset and are added to the cost of a buy or taken from the proceeds of a sell.
+func (l *tradeLedger) record(info binance.Symbol, trade *binance.TradeV3) error {
+	at := fromMillis(trade.Time)
+	quantity, err := strconv.ParseFloat(trade.Quantity, 64)
+	if err != nil {
+		return fmt.Errorf("invalid trade quantity: %s", trade.Quantity)
+	}
+	quoteQuantity, err := strconv.ParseFloat(trade.QuoteQuantity, 64)
+	if err != nil {
+		return fmt.Errorf("invalid trade quote quantity: %s", trade.QuoteQuantity)
+	}
+	commission, err := strconv.ParseFloat(trade.Commission, 64)
+	if err != nil {
+		return fmt.Errorf("invalid trade commission: %s", trade.Commission)
+	}
+
+	value, err := l.valuer.value(info.QuoteAsset, quoteQuantity, at)
+	if err != nil {
+		return err
+	}
+	fee, err := l.valuer.value(trade.CommissionAsset, commission, at)
+	if err != nil {
+		return err
+	}
+
+	if trade.IsBuyer {
+		switch trade.CommissionAsset {
+		case info.BaseAsset:
+			l.dispose(info.QuoteAsset, quoteQuantity, value, at)
+			l.acquire(info.BaseAsset, quantity-commission, value, at)
+		case info.QuoteAsset:
+			l.dispose(info.QuoteAsset, quoteQuantity+commission, value+fee, at)
+			l.acquire(info.BaseAsset, quantity, value+fee, at)
+		default:
+			l.dispose(info.QuoteAsset, quoteQuantity, value, at)
+			l.dispose(trade.CommissionAsset, commission, fee, at)
+			l.acquire(info.BaseAsset, quantity, value+fee, at)
+		}
+		return nil
+	}
+
+	switch trade.CommissionAsset {
+	case info.QuoteAsset:
+		l.dispose(info.BaseAsset, quantity, value-fee, at)
+		l.acquire(info.QuoteAsset, quoteQuantity-commission, value-fee, at)
+	case info.BaseAsset:
+		l.dispose(info.BaseAsset, quantity+commission, value, at)
+		l.acquire(info.QuoteAsset, quoteQuantity, value, at)
+	default:
+		l.dispose(info.BaseAsset, quantity, value-fee, at)
+		l.dispose(trade.CommissionAsset, commission, fee, at)
+		l.acquire(info.QuoteAsset, quoteQuantity, value, at)
+	}
+	return nil
+}
+
+// getTradedSymbols returns the symbols to load trades for. Binance cannot list the symbols an account has traded, so
+// by default every trading symbol of an asset with a balance is used. Extra symbols cover positions already closed.
+func getTradedSymbols(account *binance.Account, symbols []binance.Symbol, extra []string) ([]binance.Symbol, error) {
+	held := make(map[string]bool)
+	for _, balance := range account.Balances {
+		if parseBalanceTotal(balance.Free, balance.Locked) > 0 {
+			held[balance.Asset] = true
+		}
+	}
+
+	symbolMap := getSymbolMap(symbols)
+	selected := make(map[string]binance.Symbol)
+	for _, symbol := range symbols {
+		if held[symbol.BaseAsset] && symbol.Status == string(binance.SymbolStatusTypeTrading) {
+			selected[symbol.Symbol] = symbol
+		}
+	}
+	for _, name := range extra {
+		info, ok := symbolMap[strings.ToUpper(name)]
+		if !ok {
+			return nil, errors.New("unknown symbol: " + name)
+		}
+		selected[info.Symbol] = info
+	}
+
+	traded := make([]binance.Symbol, 0, len(selected))
+	for _, symbol := range selected {
+		traded = append(traded, symbol)
+	}
+	sort.Slice(traded, func(i, j int) bool {
+		return traded[i].Symbol < traded[j].Symbol
+	})
+	return traded, nil
+}
+
+// buildTradeLedger loads the full trade history of the symbols and records it in time order.
+func buildTradeLedger(client *binance.Client, store *marketStore, symbols, traded []binance.Symbol, method costbasis.Method, quote string) (*tradeLedger, error) {
+	type symbolTrade struct {
+		info  binance.Symbol
+		trade *binance.TradeV3
+	}
+
+	var trades []symbolTrade
+	for _, info := range traded {
+		history, err := listAllTrades(client, info.Symbol)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", info.Symbol, err)
+		}
+		for _, trade := range history {
+			trades = append(trades, symbolTrade{info, trade})
+		}
+	}
+	sort.SliceStable(trades, func(i, j int) bool {
+		return trades[i].trade.Time < trades[j].trade.Time
+	})
+
+	ledger := &tradeLedger{Ledger: costbasis.NewLedger(method)}
+	if len(trades) == 0 {
+		ledger.valuer = &assetValuer{quote: quote}
+		return ledger, nil
+	}
+
+	prices := newHistoricalPrices(client, store, fromMillis(trades[0].trade.Time), fromMillis(trades[len(trades)-1].trade.Time))
+	ledger.valuer = &assetValuer{graph: newConversionGraph(symbols), prices: prices, quote: quote}
+	for _, entry := range trades {
+		if err := ledger.record(entry.info, entry.trade); err != nil {
+			return nil, fmt.Errorf("%s trade %d: %s", entry.info.Symbol, entry.trade.ID, err)
+		}
+	}
+	return ledger, nil
+}
+
+func addPnLCommand(scope *console.Scope, client *binance.Client, symbols []binance.Symbol) {
+	var methodName, quote string
+	var extra []string
+	command := &console.Command{
+		Use:   "pnl",
+		Short: "Show realized and unrealized profit and loss per asset",
+		Long: `Replays the full trade history through a cost basis method (fifo, lifo or average) and shows the realized
+and unrealized profit and loss of each asset in the quote asset. Trades between two crypto assets dispose of one and
+acquire the other at the value of the trade, and fees paid in BNB or another asset count as a disposal of that asset.
+Values at the time of each trade come from hourly klines.
+
+Binance cannot list the symbols an account has traded, so the markets of every asset with a balance are loaded. Add
+closed positions with --symbols. Deposits and withdrawals are not tracked: anything sold without a matching buy has
+no cost.
+
+    pnl --method fifo --quote USDT --symbols LINKUSDT,DOTBTC
+		`,
+		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
+			defer resetFlags(cmd)
+			method, err := costbasis.ParseMethod(methodName)
+			if err != nil {
+				return err
+			}
+			quote = strings.ToUpper(quote)
+			graph := newConversionGraph(symbols)
+			if _, ok := graph.edges[quote]; !ok {
+				return errors.New("unknown quote asset: " + quote)
+			}
+
+			account, err := client.NewGetAccountService().Do(context.Background())
+			if err != nil {
+				return err
+			}
+			traded, err := getTradedSymbols(account, symbols, extra)
+			if err != nil {
+				return err
+			}
+
+			ledger, err := buildTradeLedger(client, getMarketStore(env.Configuration), symbols, traded, method, quote)
+			if err != nil {
+				return err
+			}
+
+			prices, err := getCurrentPrices(client)
+			if err != nil {
+				return err
+			}
+
+			var assets []assetPnL
+			for _, asset := range ledger.Assets() {
+				quantity, cost := ledger.Holding(asset)
+				realized := ledger.Realized(asset)
+
+				var value float64
+				if quantity > 0 {
+					route, ok := graph.findRoute(asset, quote)
+					if !ok {
+						return fmt.Errorf("no market to value %s in %s", asset, quote)
+					}
+					price, err := convertPrice(route, prices)
+					if err != nil {
+						return err
+					}
+					value = quantity * price
+				}
+
+				entry := assetPnL{
+					Asset:      asset,
+					Quantity:   quantity,
+					Cost:       cost,
+					Value:      value,
+					Unrealized: value - cost,
+					Realized:   realized,
+					Total:      value - cost + realized,
+				}
+				for _, disposal := range ledger.Disposals {
+					if disposal.Asset == asset && disposal.Unmatched {
+						entry.Unmatched = true
+						break
+					}
+				}
+				assets = append(assets, entry)
+			}
+			return output.Render(env, cmd, newPnLResult(method, quote, traded, assets))
+		},
+	}
+	command.Flags().StringVar(&methodName, "method", string(costbasis.FIFO), "Cost basis method: fifo, lifo or average")
+	command.Flags().StringVar(&quote, "quote", "USDT", "Asset to report profit and loss in")
+	command.Flags().StringSliceVar(&extra, "symbols", []string{}, "Additional symbols to load trades for")
+	command.Flags().Lookup("method").Annotations = map[string][]string{console.Suggestions: {"fifo", "lifo", "average"}}
+	command.Flags().Lookup("quote").Annotations = map[string][]string{console.Suggestions: getQuoteAssetList(symbols)}
+	output.AddFlag(command)
+	scope.AddCommand(command)
+}
+
+func getSymbolNames(symbols []binance.Symbol) []string {
+	names := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		names = append(names, symbol.Symbol)
+	}
+	return names
+}
+
+// formatGainValue colors a gain green or a loss red.
+func formatGainValue(value float64) string {
+	return formatChange(fmt.Sprint(value), fmt.Sprintf("%0.2f", value))
+}
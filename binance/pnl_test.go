@@ -0,0 +1,110 @@
+package binance
+
+import (
+	"math"
+	"testing"
+
+	binance "github.com/adshao/go-binance/v2"
+	"github.com/eliquious/mercator/costbasis"
+)
+
+func newTestTradeLedger() *tradeLedger {
+	trading := string(binance.SymbolStatusTypeTrading)
+	symbols := []binance.Symbol{
+		{Symbol: "ETHBTC", BaseAsset: "ETH", QuoteAsset: "BTC", Status: trading},
+		{Symbol: "BTCUSDT", BaseAsset: "BTC", QuoteAsset: "USDT", Status: trading},
+		{Symbol: "BNBUSDT", BaseAsset: "BNB", QuoteAsset: "USDT", Status: trading},
+	}
+	prices := &historicalPrices{klines: map[string][]*binance.Kline{
+		"ETHBTC":  {{OpenTime: 0, Close: "0.05"}},
+		"BTCUSDT": {{OpenTime: 0, Close: "40000"}},
+		"BNBUSDT": {{OpenTime: 0, Close: "400"}},
+	}}
+	return &tradeLedger{
+		Ledger: costbasis.NewLedger(costbasis.FIFO),
+		valuer: &assetValuer{graph: newConversionGraph(symbols), prices: prices, quote: "USDT"},
+	}
+}
+
+func TestTradeLedgerRecord(t *testing.T) {
+	type disposal struct {
+		asset              string
+		quantity, proceeds float64
+	}
+
+	// every trade swaps 2 ETH and 0.1 BTC, worth 4000 USDT. The fees are worth 4 USDT.
+	tests := []struct {
+		name            string
+		buyer           bool
+		commission      string
+		commissionAsset string
+		disposals       []disposal
+		acquired        string
+		quantity, cost  float64
+	}{
+		{"buy with base fee", true, "0.002", "ETH", []disposal{{"BTC", 0.1, 4000}}, "ETH", 1.998, 4000},
+		{"buy with quote fee", true, "0.0001", "BTC", []disposal{{"BTC", 0.1001, 4004}}, "ETH", 2, 4004},
+		{"buy with bnb fee", true, "0.01", "BNB", []disposal{{"BTC", 0.1, 4000}, {"BNB", 0.01, 4}}, "ETH", 2, 4004},
+		{"sell with quote fee", false, "0.0001", "BTC", []disposal{{"ETH", 2, 3996}}, "BTC", 0.0999, 3996},
+		{"sell with base fee", false, "0.002", "ETH", []disposal{{"ETH", 2.002, 4000}}, "BTC", 0.1, 4000},
+		{"sell with bnb fee", false, "0.01", "BNB", []disposal{{"ETH", 2, 3996}, {"BNB", 0.01, 4}}, "BTC", 0.1, 4000},
+	}
+	info := binance.Symbol{Symbol: "ETHBTC", BaseAsset: "ETH", QuoteAsset: "BTC"}
+	for _, test := range tests {
+		ledger := newTestTradeLedger()
+		trade := &binance.TradeV3{
+			Symbol:          "ETHBTC",
+			Price:           "0.05",
+			Quantity:        "2",
+			QuoteQuantity:   "0.1",
+			Commission:      test.commission,
+			CommissionAsset: test.commissionAsset,
+			Time:            3600000,
+			IsBuyer:         test.buyer,
+		}
+		if err := ledger.record(info, trade); err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+
+		if len(ledger.Disposals) != len(test.disposals) {
+			t.Fatalf("%s: expected %d disposals, got %d", test.name, len(test.disposals), len(ledger.Disposals))
+		}
+		for index, expected := range test.disposals {
+			actual := ledger.Disposals[index]
+			if actual.Asset != expected.asset || math.Abs(actual.Quantity-expected.quantity) > 1e-9 || math.Abs(actual.Proceeds-expected.proceeds) > 1e-6 {
+				t.Errorf("%s[%d]: expected %+v, got %+v", test.name, index, expected, actual)
+			}
+		}
+
+		quantity, cost := ledger.Holding(test.acquired)
+		if math.Abs(quantity-test.quantity) > 1e-9 || math.Abs(cost-test.cost) > 1e-6 {
+			t.Errorf("%s: expected %f %s costing %f, got %f costing %f", test.name, test.quantity, test.acquired, test.cost, quantity, cost)
+		}
+	}
+}
+
+func TestTradeLedgerSkipsQuote(t *testing.T) {
+	ledger := newTestTradeLedger()
+	info := binance.Symbol{Symbol: "BTCUSDT", BaseAsset: "BTC", QuoteAsset: "USDT"}
+	trade := &binance.TradeV3{
+		Symbol:          "BTCUSDT",
+		Price:           "40000",
+		Quantity:        "1",
+		QuoteQuantity:   "40000",
+		Commission:      "40",
+		CommissionAsset: "USDT",
+		Time:            3600000,
+		IsBuyer:         true,
+	}
+	if err := ledger.record(info, trade); err != nil {
+		t.Fatal(err)
+	}
+
+	// the reporting currency has no lots or disposals
+	if len(ledger.Disposals) != 0 {
+		t.Errorf("expected no disposals, got %+v", ledger.Disposals)
+	}
+	if quantity, cost := ledger.Holding("BTC"); quantity != 1 || cost != 40040 {
+		t.Errorf("expected 1 BTC costing 40040, got %f costing %f", quantity, cost)
+	}
+}
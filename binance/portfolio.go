@@ -0,0 +1,220 @@
+package binance
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	binance "github.com/adshao/go-binance/v2"
+	"github.com/eliquious/console"
+	"github.com/eliquious/mercator/output"
+)
+
+// maxConversionHops is the maximum number of markets used to convert an asset.
+const maxConversionHops = 3
+
+// bridgeAssets are tried first when routing through intermediate markets because they have the most liquid markets.
+var bridgeAssets = []string{"USDT", "BTC", "BUSD", "ETH", "BNB"}
+
+// conversionStep converts one asset to another through a market. The price is inverted when selling the quote asset
+// for the base asset.
+type conversionStep struct {
+	from    string
+	to      string
+	symbol  string
+	inverse bool
+}
+
+// conversionGraph connects assets through the markets that are trading.
+type conversionGraph struct {
+	edges map[string][]conversionStep
+}
+
+func newConversionGraph(symbols []binance.Symbol) *conversionGraph {
+	graph := &conversionGraph{edges: make(map[string][]conversionStep)}
+	for _, symbol := range symbols {
+		if symbol.Status != string(binance.SymbolStatusTypeTrading) {
+			continue
+		}
+		graph.edges[symbol.BaseAsset] = append(graph.edges[symbol.BaseAsset], conversionStep{symbol.BaseAsset, symbol.QuoteAsset, symbol.Symbol, false})
+		graph.edges[symbol.QuoteAsset] = append(graph.edges[symbol.QuoteAsset], conversionStep{symbol.QuoteAsset, symbol.BaseAsset, symbol.Symbol, true})
+	}
+
+	// visit the bridge assets first so routes go through liquid markets
+	for asset, steps := range graph.edges {
+		sort.SliceStable(steps, func(i, j int) bool {
+			return bridgeRank(steps[i].to) < bridgeRank(steps[j].to)
+		})
+		graph.edges[asset] = steps
+	}
+	return graph
+}
+
+func bridgeRank(asset string) int {
+	for index, bridge := range bridgeAssets {
+		if asset == bridge {
+			return index
+		}
+	}
+	return len(bridgeAssets)
+}
+
+// findRoute returns the shortest list of markets that converts one asset to another.
+func (g *conversionGraph) findRoute(from, to string) ([]conversionStep, bool) {
+	if from == to {
+		return []conversionStep{}, true
+	}
+
+	previous := map[string]conversionStep{}
+	visited := map[string]bool{from: true}
+	queue := []string{from}
+	for depth := 0; depth < maxConversionHops && len(queue) > 0; depth++ {
+		var next []string
+		for _, asset := range queue {
+			for _, step := range g.edges[asset] {
+				if visited[step.to] {
+					continue
+				}
+				visited[step.to] = true
+				previous[step.to] = step
+
+				if step.to == to {
+					var route []conversionStep
+					for current := to; current != from; current = previous[current].from {
+						route = append([]conversionStep{previous[current]}, route...)
+					}
+					return route, true
+				}
+				next = append(next, step.to)
+			}
+		}
+		queue = next
+	}
+	return nil, false
+}
+
+// convertPrice returns the price of one unit of the first asset of the route in the last asset.
+func convertPrice(route []conversionStep, prices map[string]string) (float64, error) {
+	rate := 1.0
+	for _, step := range route {
+		value, ok := prices[step.symbol]
+		if !ok {
+			return 0, fmt.Errorf("no price for %s", step.symbol)
+		}
+		price, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, fmt.Errorf("could not convert price: %s %s", step.symbol, value)
+		}
+
+		if step.inverse {
+			if price <= 0 {
+				return 0, fmt.Errorf("%s has no price", step.symbol)
+			}
+			rate /= price
+		} else {
+			rate *= price
+		}
+	}
+	return rate, nil
+}
+
+func formatRoute(asset string, route []conversionStep) string {
+	if len(route) == 0 {
+		return "-"
+	}
+
+	assets := []string{asset}
+	for _, step := range route {
+		assets = append(assets, step.to)
+	}
+	return strings.Join(assets, " → ")
+}
+
+// holding is the value of a balance in the portfolio.
+type holding struct {
+	asset string
+	total float64
+	price float64
+	value float64
+	route []conversionStep
+	err   error
+}
+
+func addPortfolioCommand(scope *console.Scope, client *binance.Client, traders *traders, symbols []binance.Symbol) {
+	var quote string
+	command := &console.Command{
+		Use:   "portfolio",
+		Short: "Value the account balances in a quote asset",
+		Long: `Values every non-zero balance in the quote asset and shows its share of the portfolio. Assets without a
+direct market are converted through intermediate markets, preferring USDT, BTC, BUSD, ETH and BNB.
+
+    portfolio --quote EUR
+		`,
+		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
+			defer resetFlags(cmd)
+			quote := strings.ToUpper(quote)
+			graph := newConversionGraph(symbols)
+			if _, ok := graph.edges[quote]; !ok {
+				return errors.New("unknown quote asset: " + quote)
+			}
+
+			t, err := traders.get(env, cmd)
+			if err != nil {
+				return err
+			}
+			account, err := t.getAccount()
+			if err != nil {
+				return err
+			}
+
+			prices, err := getCurrentPrices(client)
+			if err != nil {
+				return err
+			}
+
+			var holdings []holding
+			for _, balance := range account.Balances {
+				amount := parseBalanceTotal(balance.Free, balance.Locked)
+				if amount <= 0 {
+					continue
+				}
+
+				h := holding{asset: balance.Asset, total: amount}
+				route, ok := graph.findRoute(balance.Asset, quote)
+				if !ok {
+					h.err = errors.New("no market")
+				} else if h.price, h.err = convertPrice(route, prices); h.err == nil {
+					h.route = route
+					h.value = amount * h.price
+				}
+				holdings = append(holdings, h)
+			}
+
+			// sort by value with the assets that could not be valued last
+			sort.SliceStable(holdings, func(i, j int) bool {
+				if (holdings[i].err == nil) != (holdings[j].err == nil) {
+					return holdings[i].err == nil
+				}
+				return holdings[i].value > holdings[j].value
+			})
+			return output.Render(env, cmd, newPortfolioResult(isPaper(t), quote, holdings))
+		},
+	}
+	command.Flags().StringVar(&quote, "quote", "USDT", "Asset to value the portfolio in")
+	command.Flags().Bool("dry-run", false, "Value the paper trading balances")
+	command.Flags().Lookup("quote").Annotations = map[string][]string{console.Suggestions: getQuoteAssetList(symbols)}
+	output.AddFlag(command)
+	scope.AddCommand(command)
+}
+
+// getQuoteAssetList returns the sorted quote assets.
+func getQuoteAssetList(symbols []binance.Symbol) []string {
+	assets := make([]string, 0, 16)
+	for asset := range getQuoteAssetMap(symbols) {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+	return assets
+}
@@ -0,0 +1,263 @@
+package binance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	binance "github.com/adshao/go-binance/v2"
+	"github.com/eliquious/console"
+	"github.com/eliquious/mercator/output"
+	"github.com/gookit/color"
+)
+
+func addProfitCommand(scope *console.Scope, client *binance.Client, symbols []binance.Symbol) {
+	var price float64
+	command := &console.Command{
+		Use:           "profit",
+		Short:         "Calculate the profit of the account balance if sold at a future price",
+		ValidateArgs:  console.MinimumArgs(1),
+		RequiredFlags: []string{"price"},
+		Suggestions: func(env *console.Environment, args []string) []string {
+			return getSymbolList(symbols)
+		},
+		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("requires 1 symbol")
+			}
+
+			if !cmd.Flags().Changed("price") {
+				return errors.New("price is required")
+			} else if price <= 0 {
+				return errors.New("price must be positive")
+			}
+
+			info, err := getSymbolInfo(symbols, strings.ToUpper(args[0]))
+			if err != nil {
+				return err
+			}
+
+			free, locked, err := getAssetBalance(client, info.BaseAsset)
+			if err != nil {
+				return err
+			}
+			shares := free + locked
+			if shares <= 0 {
+				return fmt.Errorf("no %s balance", info.BaseAsset)
+			}
+
+			prices, err := getCurrentPrices(client)
+			if err != nil {
+				return err
+			}
+			currentPrice, err := strconv.ParseFloat(prices[info.Symbol], 64)
+			if err != nil {
+				return errors.New("could not parse current price")
+			}
+
+			trades, err := listAllTrades(client, info.Symbol)
+			if err != nil {
+				return err
+			}
+			averageCost := getAverageCost(trades)
+
+			return output.Render(env, cmd, newProfitResult(info, shares, price, currentPrice, averageCost))
+		},
+	}
+	command.Flags().Float64VarP(&price, "price", "p", 0, "Future sell price")
+	output.AddFlag(command)
+	scope.AddCommand(command)
+}
+
+// getAssetBalance returns the free and locked account balance for an asset.
+func getAssetBalance(client *binance.Client, asset string) (float64, float64, error) {
+	return getTraderBalance(&exchangeTrader{client: client}, asset)
+}
+
+// getTraderBalance returns the free and locked balance for an asset from the exchange or paper account.
+func getTraderBalance(t trader, asset string) (float64, float64, error) {
+	account, err := t.getAccount()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, balance := range account.Balances {
+		if balance.Asset == asset {
+			free, _ := strconv.ParseFloat(balance.Free, 64)
+			locked, _ := strconv.ParseFloat(balance.Locked, 64)
+			return free, locked, nil
+		}
+	}
+	return 0, 0, nil
+}
+
+// getAverageCost returns the average cost of the shares currently held based on the trade fills. Buys increase the
+// cost basis while sells reduce it proportionally, leaving the average unchanged.
+func getAverageCost(trades []*binance.TradeV3) float64 {
+	var shares, cost float64
+	for _, trade := range trades {
+		price, err := strconv.ParseFloat(trade.Price, 64)
+		if err != nil {
+			continue
+		}
+		quantity, err := strconv.ParseFloat(trade.Quantity, 64)
+		if err != nil {
+			continue
+		}
+
+		if trade.IsBuyer {
+			shares += quantity
+			cost += price * quantity
+		} else if shares > 0 {
+			sold := math.Min(quantity, shares)
+			cost -= cost * sold / shares
+			shares -= sold
+		}
+	}
+
+	if shares <= 0 {
+		return 0
+	}
+	return cost / shares
+}
+
+func formatGain(info binance.Symbol, value, basis float64) string {
+	gain := value - basis
+	text := fmt.Sprintf("%s %s (%0.2f%%)", formatQuotePrice(info, gain), info.QuoteAsset, gain/basis*100)
+	if gain < 0 {
+		return color.Red.Render(text)
+	}
+	return color.Green.Render(text)
+}
+
+// bnbDiscount is the discount on trading fees when they are paid with BNB.
+const bnbDiscount = 0.25
+
+func addEstimateCommand(scope *console.Scope, client *binance.Client, symbols []binance.Symbol) {
+	var inv, buy, sell float64
+	var taker, bnb bool
+	command := &console.Command{
+		Use:   "estimate",
+		Short: "Estimate the profit of buying at one price and selling at another including fees",
+		Long: `Spends the investment amount to buy at the buy price and sells everything at the sell price. The quantity
+is rounded down to the symbol's lot size and the fees are taken from the account's maker (default) or taker
+commission. Use --bnb if fees are paid with BNB to apply the discount.
+
+    estimate BTCUSDT --inv 1000 --buy 30000 --sell 33000
+		`,
+		ValidateArgs:  console.MinimumArgs(1),
+		RequiredFlags: []string{"inv", "buy", "sell"},
+		Suggestions: func(env *console.Environment, args []string) []string {
+			return getSymbolList(symbols)
+		},
+		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
+			defer resetFlags(cmd)
+			if len(args) != 1 {
+				return errors.New("requires 1 symbol")
+			}
+
+			if !cmd.Flags().Changed("inv") || inv <= 0 {
+				return errors.New("investment amount is required")
+			}
+			if !cmd.Flags().Changed("buy") || buy <= 0 {
+				return errors.New("buy price is required")
+			}
+			if !cmd.Flags().Changed("sell") || sell <= 0 {
+				return errors.New("sell price is required")
+			}
+
+			info, err := getSymbolInfo(symbols, strings.ToUpper(args[0]))
+			if err != nil {
+				return err
+			}
+
+			filters, err := getSymbolFilters(info)
+			if err != nil {
+				return err
+			}
+
+			rate, err := getCommissionRate(client, taker)
+			if err != nil {
+				return err
+			}
+			if bnb {
+				rate *= 1 - bnbDiscount
+			}
+
+			quantity := filters.roundQuantity(inv / buy)
+			if quantity <= 0 {
+				return errors.New("investment amount is below the lot size")
+			}
+			result := newEstimateResult(info, filters, quantity, buy, sell, rate, estimateRoundTrip(filters, quantity, buy, sell, rate, bnb))
+			if err := filters.validateOrder(buy, quantity); err != nil {
+				result.Warning = err.Error()
+			}
+			return output.Render(env, cmd, result)
+		},
+	}
+	command.Flags().Float64VarP(&inv, "inv", "i", 0, "Investment amount in the quote asset")
+	command.Flags().Float64Var(&buy, "buy", 0, "Buy price")
+	command.Flags().Float64Var(&sell, "sell", 0, "Sell price")
+	command.Flags().BoolVar(&taker, "taker", false, "Use the taker commission instead of the maker commission")
+	command.Flags().BoolVar(&bnb, "bnb", false, "Fees are paid with BNB at a discount")
+	output.AddFlag(command)
+	scope.AddCommand(command)
+}
+
+// getCommissionRate returns the account's maker or taker commission as a fraction.
+func getCommissionRate(client *binance.Client, taker bool) (float64, error) {
+	account, err := client.NewGetAccountService().Do(context.Background())
+	if err != nil {
+		return 0, err
+	}
+
+	// commissions are given in basis points
+	if taker {
+		return float64(account.TakerCommission) / 10000, nil
+	}
+	return float64(account.MakerCommission) / 10000, nil
+}
+
+// roundTripEstimate is the result of buying and then selling a quantity including fees.
+type roundTripEstimate struct {
+	cost         float64
+	spent        float64
+	buyFee       float64
+	sellQuantity float64
+	proceeds     float64
+	sellFee      float64
+	dust         float64
+	net          float64
+	breakEven    float64
+}
+
+// estimateRoundTrip calculates the result of buying the quantity and selling it again. Without BNB the buy fee is
+// taken from the base asset received, which reduces the quantity that can be sold. With BNB both fees are paid
+// separately and the whole quantity is sold.
+func estimateRoundTrip(filters symbolFilters, quantity, buy, sell, rate float64, bnb bool) roundTripEstimate {
+	var estimate roundTripEstimate
+	estimate.cost = quantity * buy
+	estimate.buyFee = estimate.cost * rate
+
+	received := quantity
+	if !bnb {
+		received = quantity * (1 - rate)
+	}
+	estimate.sellQuantity = filters.roundQuantity(received)
+	estimate.dust = received - estimate.sellQuantity
+	estimate.proceeds = estimate.sellQuantity * sell
+	estimate.sellFee = estimate.proceeds * rate
+
+	estimate.spent = estimate.cost
+	if bnb {
+		estimate.spent += estimate.buyFee
+	}
+	estimate.net = estimate.proceeds - estimate.sellFee - estimate.spent
+	if estimate.sellQuantity > 0 {
+		estimate.breakEven = estimate.spent / (estimate.sellQuantity * (1 - rate))
+	}
+	return estimate
+}
@@ -0,0 +1,1462 @@
+package binance
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	binance "github.com/adshao/go-binance/v2"
+	"github.com/eliquious/mercator/backtest"
+	"github.com/eliquious/mercator/costbasis"
+	"github.com/eliquious/mercator/output"
+	"github.com/gookit/color"
+	"github.com/olekukonko/tablewriter"
+)
+
+// The results below are rendered by the output package. Prices and quantities keep the text from the API for the
+// table format so the console output is unchanged.
+
+// priceResult is the result of symbol-price.
+type priceResult struct {
+	Prices []symbolPrice `json:"prices" yaml:"prices"`
+}
+
+type symbolPrice struct {
+	Symbol string  `json:"symbol" yaml:"symbol"`
+	Price  float64 `json:"price" yaml:"price"`
+	Error  string  `json:"error,omitempty" yaml:"error,omitempty"`
+
+	price string
+}
+
+func newPriceResult(currentPrices map[string]string, symbols []string) (*priceResult, error) {
+	result := &priceResult{Prices: make([]symbolPrice, 0, len(symbols))}
+	for _, symbol := range symbols {
+		text, ok := currentPrices[symbol]
+		if !ok {
+			result.Prices = append(result.Prices, symbolPrice{Symbol: symbol, Error: "unknown symbol"})
+			continue
+		}
+
+		price, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not convert price: %s %s", symbol, text)
+		}
+		result.Prices = append(result.Prices, symbolPrice{Symbol: symbol, Price: price, price: text})
+	}
+	return result, nil
+}
+
+func (r *priceResult) Print(w io.Writer) {
+	for _, price := range r.Prices {
+		if price.Error != "" {
+			fmt.Fprintf(w, "%s:  %s\n", color.LightGreen.Render(price.Symbol), color.Red.Render(price.Error))
+			continue
+		}
+		fmt.Fprintf(w, "%s:  %s\n", color.LightGreen.Render(price.Symbol), price.price)
+	}
+}
+
+func (r *priceResult) Records() [][]string {
+	records := [][]string{{"symbol", "price", "error"}}
+	for _, price := range r.Prices {
+		value := ""
+		if price.Error == "" {
+			value = output.FormatFloat(price.Price)
+		}
+		records = append(records, []string{price.Symbol, value, price.Error})
+	}
+	return records
+}
+
+// balanceResult is the result of account-balance. Only non-zero balances are included.
+type balanceResult struct {
+	Paper    bool           `json:"paper" yaml:"paper"`
+	Balances []assetBalance `json:"balances" yaml:"balances"`
+}
+
+type assetBalance struct {
+	Asset  string  `json:"asset" yaml:"asset"`
+	Free   float64 `json:"free" yaml:"free"`
+	Locked float64 `json:"locked" yaml:"locked"`
+	Total  float64 `json:"total" yaml:"total"`
+
+	free   string
+	locked string
+}
+
+func newBalanceResult(paper bool, balances []binance.Balance) *balanceResult {
+	result := &balanceResult{Paper: paper, Balances: []assetBalance{}}
+	for _, balance := range balances {
+		free, _ := strconv.ParseFloat(balance.Free, 64)
+		locked, _ := strconv.ParseFloat(balance.Locked, 64)
+		if free > 0 || locked > 0 {
+			result.Balances = append(result.Balances, assetBalance{
+				Asset:  balance.Asset,
+				Free:   free,
+				Locked: locked,
+				Total:  free + locked,
+				free:   balance.Free,
+				locked: balance.Locked,
+			})
+		}
+	}
+	return result
+}
+
+func (r *balanceResult) Print(w io.Writer) {
+	fmt.Fprintln(w)
+	printPaperHeader(w, r.Paper)
+	fmt.Fprintln(w, color.LightWhite.Render("Account Balance(s):"))
+	for _, balance := range r.Balances {
+		fmt.Fprintf(w, "%s:\n", color.LightGreen.Render(balance.Asset))
+		fmt.Fprintf(w, "  %s:     %s\n", color.LightYellow.Render("Free"), balance.free)
+		fmt.Fprintf(w, "  %s:   %s\n", color.LightYellow.Render("Locked"), balance.locked)
+		fmt.Fprintf(w, "  %s:    %0.8f\n", color.LightYellow.Render("Total"), balance.Total)
+	}
+}
+
+func (r *balanceResult) Records() [][]string {
+	records := [][]string{{"asset", "free", "locked", "total"}}
+	for _, balance := range r.Balances {
+		records = append(records, []string{
+			balance.Asset,
+			output.FormatFloat(balance.Free),
+			output.FormatFloat(balance.Locked),
+			output.FormatFloat(balance.Total),
+		})
+	}
+	return records
+}
+
+// tradesResult is the result of account-trades.
+type tradesResult struct {
+	Paper  bool           `json:"paper" yaml:"paper"`
+	Symbol string         `json:"symbol" yaml:"symbol"`
+	Trades []accountTrade `json:"trades" yaml:"trades"`
+}
+
+type accountTrade struct {
+	ID       int64     `json:"id" yaml:"id"`
+	Time     time.Time `json:"time" yaml:"time"`
+	Price    float64   `json:"price" yaml:"price"`
+	Quantity float64   `json:"quantity" yaml:"quantity"`
+	Side     string    `json:"side" yaml:"side"`
+
+	price    string
+	quantity string
+}
+
+func newTradesResult(paper bool, symbol string, trades []*binance.TradeV3) *tradesResult {
+	result := &tradesResult{Paper: paper, Symbol: symbol, Trades: make([]accountTrade, 0, len(trades))}
+	for _, trade := range trades {
+		price, _ := strconv.ParseFloat(trade.Price, 64)
+		quantity, _ := strconv.ParseFloat(trade.Quantity, 64)
+		side := string(binance.SideTypeSell)
+		if trade.IsBuyer {
+			side = string(binance.SideTypeBuy)
+		}
+
+		result.Trades = append(result.Trades, accountTrade{
+			ID:       trade.ID,
+			Time:     fromMillis(trade.Time).UTC(),
+			Price:    price,
+			Quantity: quantity,
+			Side:     side,
+			price:    trade.Price,
+			quantity: trade.Quantity,
+		})
+	}
+	return result
+}
+
+func (r *tradesResult) Print(w io.Writer) {
+	printPaperHeader(w, r.Paper)
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"ID", "Timestamp", "Price", "Quantity", "Side"})
+	for _, trade := range r.Trades {
+		table.Append([]string{
+			strconv.FormatInt(trade.ID, 10),
+			trade.Time.Local().Format("2006-01-02T15:04:05"),
+			trade.price,
+			trade.quantity,
+			formatSide(binance.SideType(trade.Side)),
+		})
+	}
+	table.Render() // Send output
+}
+
+func (r *tradesResult) Records() [][]string {
+	records := [][]string{{"id", "time", "price", "quantity", "side"}}
+	for _, trade := range r.Trades {
+		records = append(records, []string{
+			strconv.FormatInt(trade.ID, 10),
+			trade.Time.Format(time.RFC3339),
+			output.FormatFloat(trade.Price),
+			output.FormatFloat(trade.Quantity),
+			trade.Side,
+		})
+	}
+	return records
+}
+
+// depthResult is the result of depth. Asks and bids are ordered from the best price.
+type depthResult struct {
+	Symbol       string       `json:"symbol" yaml:"symbol"`
+	LastUpdateID int64        `json:"lastUpdateId" yaml:"lastUpdateId"`
+	Asks         []depthLevel `json:"asks" yaml:"asks"`
+	Bids         []depthLevel `json:"bids" yaml:"bids"`
+}
+
+type depthLevel struct {
+	Price    float64 `json:"price" yaml:"price"`
+	Quantity float64 `json:"quantity" yaml:"quantity"`
+
+	price string
+}
+
+func newDepthResult(symbol string, resp *binance.DepthResponse, levels int) (*depthResult, error) {
+	result := &depthResult{Symbol: symbol, LastUpdateID: resp.LastUpdateID, Asks: []depthLevel{}, Bids: []depthLevel{}}
+	for index := 0; index < len(resp.Asks) && index < levels; index++ {
+		level, err := newDepthLevel(resp.Asks[index].Price, resp.Asks[index].Quantity)
+		if err != nil {
+			return nil, err
+		}
+		result.Asks = append(result.Asks, level)
+	}
+	for index := 0; index < len(resp.Bids) && index < levels; index++ {
+		level, err := newDepthLevel(resp.Bids[index].Price, resp.Bids[index].Quantity)
+		if err != nil {
+			return nil, err
+		}
+		result.Bids = append(result.Bids, level)
+	}
+	return result, nil
+}
+
+func newDepthLevel(price, quantity string) (depthLevel, error) {
+	p, err := strconv.ParseFloat(price, 64)
+	if err != nil {
+		return depthLevel{}, err
+	}
+	q, err := strconv.ParseFloat(quantity, 64)
+	if err != nil {
+		return depthLevel{}, err
+	}
+	return depthLevel{Price: p, Quantity: q, price: price}, nil
+}
+
+func (r *depthResult) Print(w io.Writer) {
+	fmt.Fprintln(w, "\n      ", r.Symbol, "Order Book")
+	fmt.Fprintln(w, "------------------------------")
+	for index := len(r.Asks) - 1; index >= 0; index-- {
+		ask := r.Asks[index]
+		fmt.Fprintf(w, " % 12s %s\n", color.Magenta.Render(ask.price), padLeft(fmt.Sprintf("%0.4f", ask.Quantity), " ", 15))
+	}
+	fmt.Fprintln(w)
+	for _, bid := range r.Bids {
+		fmt.Fprintf(w, " % 12s %s\n", color.Cyan.Render(bid.price), padLeft(fmt.Sprintf("%0.4f", bid.Quantity), " ", 15))
+	}
+	fmt.Fprintln(w, "------------ -----------------")
+	fmt.Fprintln(w)
+}
+
+func (r *depthResult) Records() [][]string {
+	records := [][]string{{"side", "price", "quantity"}}
+	for _, ask := range r.Asks {
+		records = append(records, []string{"ask", output.FormatFloat(ask.Price), output.FormatFloat(ask.Quantity)})
+	}
+	for _, bid := range r.Bids {
+		records = append(records, []string{"bid", output.FormatFloat(bid.Price), output.FormatFloat(bid.Quantity)})
+	}
+	return records
+}
+
+// symbolDetailResult is the result of symbol-detail.
+type symbolDetailResult struct {
+	Symbol             string   `json:"symbol" yaml:"symbol"`
+	Status             string   `json:"status" yaml:"status"`
+	BaseAsset          string   `json:"baseAsset" yaml:"baseAsset"`
+	BaseAssetPrecision int      `json:"baseAssetPrecision" yaml:"baseAssetPrecision"`
+	QuoteAsset         string   `json:"quoteAsset" yaml:"quoteAsset"`
+	QuotePrecision     int      `json:"quotePrecision" yaml:"quotePrecision"`
+	IcebergAllowed     bool     `json:"icebergAllowed" yaml:"icebergAllowed"`
+	OcoAllowed         bool     `json:"ocoAllowed" yaml:"ocoAllowed"`
+	SpotTrading        bool     `json:"spotTrading" yaml:"spotTrading"`
+	MarginTrading      bool     `json:"marginTrading" yaml:"marginTrading"`
+	OrderTypes         []string `json:"orderTypes" yaml:"orderTypes"`
+}
+
+func newSymbolDetailResult(details binance.Symbol) *symbolDetailResult {
+	return &symbolDetailResult{
+		Symbol:             details.Symbol,
+		Status:             details.Status,
+		BaseAsset:          details.BaseAsset,
+		BaseAssetPrecision: details.BaseAssetPrecision,
+		QuoteAsset:         details.QuoteAsset,
+		QuotePrecision:     details.QuotePrecision,
+		IcebergAllowed:     details.IcebergAllowed,
+		OcoAllowed:         details.OcoAllowed,
+		SpotTrading:        details.IsSpotTradingAllowed,
+		MarginTrading:      details.IsMarginTradingAllowed,
+		OrderTypes:         details.OrderTypes,
+	}
+}
+
+func (r *symbolDetailResult) Print(w io.Writer) {
+	fmt.Fprintf(w, "Symbol Status: %v\nBase Asset: %s\nBase Asset Precision: %d\nQuote Asset: %s\nQuote Precision: %d\nIceberg Allowed: %s\nOCO Orders Allowed: %s\nSpot Trading: %s\nMargin Trading: %s\n",
+		r.Status,
+		r.BaseAsset,
+		r.BaseAssetPrecision,
+		r.QuoteAsset,
+		r.QuotePrecision,
+		formatBoolean(r.IcebergAllowed),
+		formatBoolean(r.OcoAllowed),
+		formatBoolean(r.SpotTrading),
+		formatBoolean(r.MarginTrading),
+	)
+	fmt.Fprintf(w, "\nSupported Order Types:\n%s\n\n", strings.Join(r.OrderTypes, "\n"))
+}
+
+func (r *symbolDetailResult) Records() [][]string {
+	return [][]string{
+		{"symbol", "status", "base_asset", "base_asset_precision", "quote_asset", "quote_precision", "iceberg_allowed", "oco_allowed", "spot_trading", "margin_trading", "order_types"},
+		{
+			r.Symbol,
+			r.Status,
+			r.BaseAsset,
+			strconv.Itoa(r.BaseAssetPrecision),
+			r.QuoteAsset,
+			strconv.Itoa(r.QuotePrecision),
+			strconv.FormatBool(r.IcebergAllowed),
+			strconv.FormatBool(r.OcoAllowed),
+			strconv.FormatBool(r.SpotTrading),
+			strconv.FormatBool(r.MarginTrading),
+			strings.Join(r.OrderTypes, " "),
+		},
+	}
+}
+
+// printPaperHeader marks paper trading output.
+func printPaperHeader(w io.Writer, paper bool) {
+	if paper {
+		fmt.Fprintln(w, color.Warn.Render("PAPER TRADING"))
+	}
+}
+
+// klinesResult is the result of klines.
+type klinesResult struct {
+	Symbol   string        `json:"symbol" yaml:"symbol"`
+	Interval string        `json:"interval" yaml:"interval"`
+	Klines   []symbolKline `json:"klines" yaml:"klines"`
+
+	info binance.Symbol
+}
+
+type symbolKline struct {
+	OpenTime    time.Time `json:"openTime" yaml:"openTime"`
+	CloseTime   time.Time `json:"closeTime" yaml:"closeTime"`
+	Open        float64   `json:"open" yaml:"open"`
+	High        float64   `json:"high" yaml:"high"`
+	Low         float64   `json:"low" yaml:"low"`
+	Close       float64   `json:"close" yaml:"close"`
+	Volume      float64   `json:"volume" yaml:"volume"`
+	QuoteVolume float64   `json:"quoteVolume" yaml:"quoteVolume"`
+	Trades      int64     `json:"trades" yaml:"trades"`
+
+	volume string
+}
+
+func newKlinesResult(info binance.Symbol, interval string, klines []*binance.Kline) (*klinesResult, error) {
+	result := &klinesResult{Symbol: info.Symbol, Interval: interval, Klines: make([]symbolKline, 0, len(klines)), info: info}
+	for _, kline := range klines {
+		candle, err := parseKline(kline)
+		if err != nil {
+			return nil, err
+		}
+		quoteVolume, err := strconv.ParseFloat(kline.QuoteAssetVolume, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quote volume: %s", kline.QuoteAssetVolume)
+		}
+
+		result.Klines = append(result.Klines, symbolKline{
+			OpenTime:    candle.openTime.UTC(),
+			CloseTime:   candle.closeTime.UTC(),
+			Open:        candle.open,
+			High:        candle.high,
+			Low:         candle.low,
+			Close:       candle.close,
+			Volume:      candle.volume,
+			QuoteVolume: quoteVolume,
+			Trades:      kline.TradeNum,
+			volume:      kline.Volume,
+		})
+	}
+	return result, nil
+}
+
+func (r *klinesResult) Print(w io.Writer) {
+	if len(r.Klines) == 0 {
+		fmt.Fprintln(w, "No klines found")
+		return
+	}
+
+	timeFormat := getKlineTimeFormat(r.Interval)
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Open Time", "Open", "High", "Low", "Close", "Change", "Volume", "Trades"})
+	for _, kline := range r.Klines {
+		change := (kline.Close - kline.Open) / kline.Open * 100
+		table.Append([]string{
+			kline.OpenTime.Local().Format(timeFormat),
+			formatQuotePrice(r.info, kline.Open),
+			formatQuotePrice(r.info, kline.High),
+			formatQuotePrice(r.info, kline.Low),
+			formatQuotePrice(r.info, kline.Close),
+			formatChange(fmt.Sprint(change), fmt.Sprintf("%0.2f%%", change)),
+			kline.volume,
+			strconv.FormatInt(kline.Trades, 10),
+		})
+	}
+	table.Render() // Send output
+}
+
+func (r *klinesResult) Records() [][]string {
+	records := [][]string{{"open_time", "open", "high", "low", "close", "volume", "close_time", "quote_volume", "trades"}}
+	for _, kline := range r.Klines {
+		records = append(records, []string{
+			kline.OpenTime.Format(time.RFC3339),
+			output.FormatFloat(kline.Open),
+			output.FormatFloat(kline.High),
+			output.FormatFloat(kline.Low),
+			output.FormatFloat(kline.Close),
+			output.FormatFloat(kline.Volume),
+			kline.CloseTime.Format(time.RFC3339),
+			output.FormatFloat(kline.QuoteVolume),
+			strconv.FormatInt(kline.Trades, 10),
+		})
+	}
+	return records
+}
+
+// ordersResult is the result of open-orders, order-status and order-history.
+type ordersResult struct {
+	Paper  bool          `json:"paper" yaml:"paper"`
+	Orders []symbolOrder `json:"orders" yaml:"orders"`
+
+	// empty is printed instead of the table when there are no orders and count adds the number of orders
+	empty string
+	count bool
+}
+
+type symbolOrder struct {
+	ID       int64     `json:"id" yaml:"id"`
+	Time     time.Time `json:"time" yaml:"time"`
+	Symbol   string    `json:"symbol" yaml:"symbol"`
+	Type     string    `json:"type" yaml:"type"`
+	Side     string    `json:"side" yaml:"side"`
+	Price    float64   `json:"price" yaml:"price"`
+	Quantity float64   `json:"quantity" yaml:"quantity"`
+	Executed float64   `json:"executed" yaml:"executed"`
+	Status   string    `json:"status" yaml:"status"`
+
+	price    string
+	quantity string
+	executed string
+}
+
+func newOrdersResult(paper bool, orders []*binance.Order) *ordersResult {
+	result := &ordersResult{Paper: paper, Orders: make([]symbolOrder, 0, len(orders))}
+	for _, order := range orders {
+		price, _ := strconv.ParseFloat(order.Price, 64)
+		quantity, _ := strconv.ParseFloat(order.OrigQuantity, 64)
+		executed, _ := strconv.ParseFloat(order.ExecutedQuantity, 64)
+		result.Orders = append(result.Orders, symbolOrder{
+			ID:       order.OrderID,
+			Time:     fromMillis(order.Time).UTC(),
+			Symbol:   order.Symbol,
+			Type:     string(order.Type),
+			Side:     string(order.Side),
+			Price:    price,
+			Quantity: quantity,
+			Executed: executed,
+			Status:   string(order.Status),
+			price:    order.Price,
+			quantity: order.OrigQuantity,
+			executed: order.ExecutedQuantity,
+		})
+	}
+	return result
+}
+
+func (r *ordersResult) Print(w io.Writer) {
+	printPaperHeader(w, r.Paper)
+	if len(r.Orders) == 0 && r.empty != "" {
+		fmt.Fprintln(w, r.empty)
+		return
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"ID", "Timestamp", "Symbol", "Type", "Side", "Price", "Quantity", "Executed", "Status"})
+	for _, order := range r.Orders {
+		table.Append([]string{
+			strconv.FormatInt(order.ID, 10),
+			order.Time.Local().Format("2006-01-02T15:04:05"),
+			order.Symbol,
+			order.Type,
+			formatSide(binance.SideType(order.Side)),
+			order.price,
+			order.quantity,
+			order.executed,
+			order.Status,
+		})
+	}
+	table.Render() // Send output
+
+	if r.count {
+		fmt.Fprintf(w, "%d order(s)\n", len(r.Orders))
+	}
+}
+
+func (r *ordersResult) Records() [][]string {
+	records := [][]string{{"id", "time", "symbol", "type", "side", "price", "quantity", "executed", "status"}}
+	for _, order := range r.Orders {
+		records = append(records, []string{
+			strconv.FormatInt(order.ID, 10),
+			order.Time.Format(time.RFC3339),
+			order.Symbol,
+			order.Type,
+			order.Side,
+			output.FormatFloat(order.Price),
+			output.FormatFloat(order.Quantity),
+			output.FormatFloat(order.Executed),
+			order.Status,
+		})
+	}
+	return records
+}
+
+// depositsResult is the result of deposit-history. Deposits are ordered from the newest.
+type depositsResult struct {
+	Deposits []assetDeposit `json:"deposits" yaml:"deposits"`
+}
+
+type assetDeposit struct {
+	Time    time.Time `json:"time" yaml:"time"`
+	Asset   string    `json:"asset" yaml:"asset"`
+	Amount  float64   `json:"amount" yaml:"amount"`
+	Address string    `json:"address" yaml:"address"`
+	TxID    string    `json:"txId" yaml:"txId"`
+	Status  string    `json:"status" yaml:"status"`
+}
+
+func newDepositsResult(deposits []*binance.Deposit) *depositsResult {
+	result := &depositsResult{Deposits: make([]assetDeposit, 0, len(deposits))}
+	for _, deposit := range deposits {
+		result.Deposits = append(result.Deposits, assetDeposit{
+			Time:    fromMillis(deposit.InsertTime).UTC(),
+			Asset:   deposit.Asset,
+			Amount:  deposit.Amount,
+			Address: deposit.Address,
+			TxID:    deposit.TxID,
+			Status:  formatTransferStatus(depositStatuses, deposit.Status),
+		})
+	}
+	return result
+}
+
+func (r *depositsResult) Print(w io.Writer) {
+	if len(r.Deposits) == 0 {
+		fmt.Fprintln(w, "No deposits found")
+		return
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Timestamp", "Asset", "Amount", "Address", "TxID", "Status"})
+	for _, deposit := range r.Deposits {
+		table.Append([]string{
+			deposit.Time.Local().Format("2006-01-02T15:04:05"),
+			deposit.Asset,
+			formatAmount(deposit.Amount),
+			deposit.Address,
+			deposit.TxID,
+			deposit.Status,
+		})
+	}
+	table.Render() // Send output
+}
+
+func (r *depositsResult) Records() [][]string {
+	records := [][]string{{"time", "asset", "amount", "address", "tx_id", "status"}}
+	for _, deposit := range r.Deposits {
+		records = append(records, []string{
+			deposit.Time.Format(time.RFC3339),
+			deposit.Asset,
+			output.FormatFloat(deposit.Amount),
+			deposit.Address,
+			deposit.TxID,
+			deposit.Status,
+		})
+	}
+	return records
+}
+
+// withdrawalsResult is the result of withdraw-history. Withdrawals are ordered from the newest.
+type withdrawalsResult struct {
+	Withdrawals []assetWithdrawal `json:"withdrawals" yaml:"withdrawals"`
+}
+
+type assetWithdrawal struct {
+	Time    time.Time `json:"time" yaml:"time"`
+	Asset   string    `json:"asset" yaml:"asset"`
+	Amount  float64   `json:"amount" yaml:"amount"`
+	Fee     float64   `json:"fee" yaml:"fee"`
+	Network string    `json:"network" yaml:"network"`
+	Address string    `json:"address" yaml:"address"`
+	TxID    string    `json:"txId" yaml:"txId"`
+	Status  string    `json:"status" yaml:"status"`
+}
+
+func newWithdrawalsResult(withdrawals []*binance.Withdraw) *withdrawalsResult {
+	result := &withdrawalsResult{Withdrawals: make([]assetWithdrawal, 0, len(withdrawals))}
+	for _, withdraw := range withdrawals {
+		result.Withdrawals = append(result.Withdrawals, assetWithdrawal{
+			Time:    fromMillis(withdraw.ApplyTime).UTC(),
+			Asset:   withdraw.Asset,
+			Amount:  withdraw.Amount,
+			Fee:     withdraw.TransactionFee,
+			Network: withdraw.Network,
+			Address: withdraw.Address,
+			TxID:    withdraw.TxID,
+			Status:  formatTransferStatus(withdrawStatuses, withdraw.Status),
+		})
+	}
+	return result
+}
+
+func (r *withdrawalsResult) Print(w io.Writer) {
+	if len(r.Withdrawals) == 0 {
+		fmt.Fprintln(w, "No withdrawals found")
+		return
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Timestamp", "Asset", "Amount", "Fee", "Network", "Address", "TxID", "Status"})
+	for _, withdraw := range r.Withdrawals {
+		table.Append([]string{
+			withdraw.Time.Local().Format("2006-01-02T15:04:05"),
+			withdraw.Asset,
+			formatAmount(withdraw.Amount),
+			formatAmount(withdraw.Fee),
+			withdraw.Network,
+			withdraw.Address,
+			withdraw.TxID,
+			withdraw.Status,
+		})
+	}
+	table.Render() // Send output
+}
+
+func (r *withdrawalsResult) Records() [][]string {
+	records := [][]string{{"time", "asset", "amount", "fee", "network", "address", "tx_id", "status"}}
+	for _, withdraw := range r.Withdrawals {
+		records = append(records, []string{
+			withdraw.Time.Format(time.RFC3339),
+			withdraw.Asset,
+			output.FormatFloat(withdraw.Amount),
+			output.FormatFloat(withdraw.Fee),
+			withdraw.Network,
+			withdraw.Address,
+			withdraw.TxID,
+			withdraw.Status,
+		})
+	}
+	return records
+}
+
+// addressBookResult is the result of withdraw-addresses. Destinations are sorted by name.
+type addressBookResult struct {
+	Addresses []addressBookEntry `json:"addresses" yaml:"addresses"`
+}
+
+type addressBookEntry struct {
+	Name    string `json:"name" yaml:"name"`
+	Asset   string `json:"asset" yaml:"asset"`
+	Network string `json:"network" yaml:"network"`
+	Address string `json:"address" yaml:"address"`
+	Tag     string `json:"tag,omitempty" yaml:"tag,omitempty"`
+}
+
+func newAddressBookResult(book map[string]withdrawAddress) *addressBookResult {
+	names := make([]string, 0, len(book))
+	for name := range book {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := &addressBookResult{Addresses: make([]addressBookEntry, 0, len(book))}
+	for _, name := range names {
+		entry := book[name]
+		result.Addresses = append(result.Addresses, addressBookEntry{
+			Name:    name,
+			Asset:   entry.Asset,
+			Network: entry.Network,
+			Address: entry.Address,
+			Tag:     entry.Tag,
+		})
+	}
+	return result
+}
+
+func (r *addressBookResult) Print(w io.Writer) {
+	if len(r.Addresses) == 0 {
+		fmt.Fprintf(w, "No addresses configured under %s\n", addressBookKey)
+		return
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Name", "Asset", "Network", "Address", "Tag"})
+	for _, entry := range r.Addresses {
+		table.Append([]string{entry.Name, entry.Asset, entry.Network, entry.Address, entry.Tag})
+	}
+	table.Render() // Send output
+}
+
+func (r *addressBookResult) Records() [][]string {
+	records := [][]string{{"name", "asset", "network", "address", "tag"}}
+	for _, entry := range r.Addresses {
+		records = append(records, []string{entry.Name, entry.Asset, entry.Network, entry.Address, entry.Tag})
+	}
+	return records
+}
+
+// portfolioResult is the result of portfolio. Holdings are ordered by value with the assets that could not be valued
+// last. The allocation is a fraction of the total value.
+type portfolioResult struct {
+	Paper    bool               `json:"paper" yaml:"paper"`
+	Quote    string             `json:"quote" yaml:"quote"`
+	Total    float64            `json:"total" yaml:"total"`
+	Holdings []portfolioHolding `json:"holdings" yaml:"holdings"`
+}
+
+type portfolioHolding struct {
+	Asset      string   `json:"asset" yaml:"asset"`
+	Total      float64  `json:"total" yaml:"total"`
+	Price      float64  `json:"price" yaml:"price"`
+	Value      float64  `json:"value" yaml:"value"`
+	Allocation float64  `json:"allocation" yaml:"allocation"`
+	Markets    []string `json:"markets" yaml:"markets"`
+	Error      string   `json:"error,omitempty" yaml:"error,omitempty"`
+
+	route []conversionStep
+}
+
+func newPortfolioResult(paper bool, quote string, holdings []holding) *portfolioResult {
+	result := &portfolioResult{Paper: paper, Quote: quote, Holdings: make([]portfolioHolding, 0, len(holdings))}
+	for _, h := range holdings {
+		if h.err == nil {
+			result.Total += h.value
+		}
+	}
+
+	for _, h := range holdings {
+		entry := portfolioHolding{Asset: h.asset, Total: h.total, Markets: []string{}}
+		if h.err != nil {
+			entry.Error = h.err.Error()
+		} else {
+			entry.Price = h.price
+			entry.Value = h.value
+			entry.route = h.route
+			for _, step := range h.route {
+				entry.Markets = append(entry.Markets, step.symbol)
+			}
+			if result.Total > 0 {
+				entry.Allocation = h.value / result.Total
+			}
+		}
+		result.Holdings = append(result.Holdings, entry)
+	}
+	return result
+}
+
+func (r *portfolioResult) Print(w io.Writer) {
+	fmt.Fprintln(w)
+	printPaperHeader(w, r.Paper)
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Asset", "Total", "Price", "Value", "Allocation", "Route"})
+	for _, h := range r.Holdings {
+		if h.Error != "" {
+			table.Append([]string{h.Asset, formatAmount(h.Total), "-", "-", "-", color.Red.Render(h.Error)})
+			continue
+		}
+
+		table.Append([]string{
+			h.Asset,
+			formatAmount(h.Total),
+			fmt.Sprintf("%0.8f", h.Price),
+			fmt.Sprintf("%0.2f", h.Value),
+			fmt.Sprintf("%0.2f%%", h.Allocation*100),
+			formatRoute(h.Asset, h.route),
+		})
+	}
+	table.SetFooter([]string{"Total", "", "", fmt.Sprintf("%0.2f %s", r.Total, r.Quote), "100.00%", ""})
+	table.Render() // Send output
+}
+
+func (r *portfolioResult) Records() [][]string {
+	records := [][]string{{"asset", "total", "price", "value", "allocation", "markets", "error"}}
+	for _, h := range r.Holdings {
+		records = append(records, []string{
+			h.Asset,
+			output.FormatFloat(h.Total),
+			output.FormatFloat(h.Price),
+			output.FormatFloat(h.Value),
+			output.FormatFloat(h.Allocation),
+			strings.Join(h.Markets, " "),
+			h.Error,
+		})
+	}
+	return records
+}
+
+// pnlResult is the result of pnl. Values are in the quote asset and the totals add up every asset.
+type pnlResult struct {
+	Method     string     `json:"method" yaml:"method"`
+	Quote      string     `json:"quote" yaml:"quote"`
+	Symbols    []string   `json:"symbols" yaml:"symbols"`
+	Assets     []assetPnL `json:"assets" yaml:"assets"`
+	Cost       float64    `json:"cost" yaml:"cost"`
+	Value      float64    `json:"value" yaml:"value"`
+	Unrealized float64    `json:"unrealized" yaml:"unrealized"`
+	Realized   float64    `json:"realized" yaml:"realized"`
+	Total      float64    `json:"total" yaml:"total"`
+}
+
+// assetPnL is the profit and loss of an asset. Unmatched is set if some of it was sold without a matching buy and
+// counted at zero cost.
+type assetPnL struct {
+	Asset      string  `json:"asset" yaml:"asset"`
+	Quantity   float64 `json:"quantity" yaml:"quantity"`
+	Cost       float64 `json:"cost" yaml:"cost"`
+	Value      float64 `json:"value" yaml:"value"`
+	Unrealized float64 `json:"unrealized" yaml:"unrealized"`
+	Realized   float64 `json:"realized" yaml:"realized"`
+	Total      float64 `json:"total" yaml:"total"`
+	Unmatched  bool    `json:"unmatched" yaml:"unmatched"`
+}
+
+func newPnLResult(method costbasis.Method, quote string, traded []binance.Symbol, assets []assetPnL) *pnlResult {
+	result := &pnlResult{Method: string(method), Quote: quote, Symbols: getSymbolNames(traded), Assets: assets}
+	for _, asset := range assets {
+		result.Cost += asset.Cost
+		result.Value += asset.Value
+		result.Unrealized += asset.Unrealized
+		result.Realized += asset.Realized
+		result.Total += asset.Total
+	}
+	return result
+}
+
+func (r *pnlResult) Print(w io.Writer) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Asset", "Quantity", "Cost Basis", "Value", "Unrealized", "Realized", "Total"})
+	var unmatched []string
+	for _, asset := range r.Assets {
+		table.Append([]string{
+			asset.Asset,
+			formatAmount(asset.Quantity),
+			fmt.Sprintf("%0.2f", asset.Cost),
+			fmt.Sprintf("%0.2f", asset.Value),
+			formatGainValue(asset.Unrealized),
+			formatGainValue(asset.Realized),
+			formatGainValue(asset.Total),
+		})
+		if asset.Unmatched {
+			unmatched = append(unmatched, asset.Asset)
+		}
+	}
+	table.SetFooter([]string{
+		"Total " + r.Quote,
+		"",
+		fmt.Sprintf("%0.2f", r.Cost),
+		fmt.Sprintf("%0.2f", r.Value),
+		fmt.Sprintf("%0.2f", r.Unrealized),
+		fmt.Sprintf("%0.2f", r.Realized),
+		fmt.Sprintf("%0.2f", r.Total),
+	})
+
+	fmt.Fprintf(w, "\n%s: %s\n", color.LightGreen.Render("Method"), r.Method)
+	fmt.Fprintf(w, "%s: %s\n\n", color.LightGreen.Render("Symbols"), strings.Join(r.Symbols, ", "))
+	table.Render() // Send output
+
+	if len(unmatched) > 0 {
+		fmt.Fprintln(w, color.Warn.Render("sold without a matching buy (treated as zero cost): "+strings.Join(unmatched, ", ")))
+	}
+}
+
+func (r *pnlResult) Records() [][]string {
+	records := [][]string{{"asset", "quantity", "cost", "value", "unrealized", "realized", "total", "unmatched"}}
+	for _, asset := range r.Assets {
+		records = append(records, []string{
+			asset.Asset,
+			output.FormatFloat(asset.Quantity),
+			output.FormatFloat(asset.Cost),
+			output.FormatFloat(asset.Value),
+			output.FormatFloat(asset.Unrealized),
+			output.FormatFloat(asset.Realized),
+			output.FormatFloat(asset.Total),
+			strconv.FormatBool(asset.Unmatched),
+		})
+	}
+	return records
+}
+
+// estimateResult is the result of estimate. Amounts are in the quote asset except the quantities, which are in the
+// base asset. The fee rate and return are fractions.
+type estimateResult struct {
+	Symbol       string  `json:"symbol" yaml:"symbol"`
+	FeeRate      float64 `json:"feeRate" yaml:"feeRate"`
+	Quantity     float64 `json:"quantity" yaml:"quantity"`
+	BuyPrice     float64 `json:"buyPrice" yaml:"buyPrice"`
+	Cost         float64 `json:"cost" yaml:"cost"`
+	BuyFee       float64 `json:"buyFee" yaml:"buyFee"`
+	SellQuantity float64 `json:"sellQuantity" yaml:"sellQuantity"`
+	SellPrice    float64 `json:"sellPrice" yaml:"sellPrice"`
+	Proceeds     float64 `json:"proceeds" yaml:"proceeds"`
+	SellFee      float64 `json:"sellFee" yaml:"sellFee"`
+	Dust         float64 `json:"dust" yaml:"dust"`
+	NetProfit    float64 `json:"netProfit" yaml:"netProfit"`
+	BreakEven    float64 `json:"breakEven" yaml:"breakEven"`
+	Return       float64 `json:"return" yaml:"return"`
+	Warning      string  `json:"warning,omitempty" yaml:"warning,omitempty"`
+
+	info    binance.Symbol
+	filters symbolFilters
+	spent   float64
+}
+
+func newEstimateResult(info binance.Symbol, filters symbolFilters, quantity, buy, sell, rate float64, estimate roundTripEstimate) *estimateResult {
+	return &estimateResult{
+		Symbol:       info.Symbol,
+		FeeRate:      rate,
+		Quantity:     quantity,
+		BuyPrice:     buy,
+		Cost:         estimate.cost,
+		BuyFee:       estimate.buyFee,
+		SellQuantity: estimate.sellQuantity,
+		SellPrice:    sell,
+		Proceeds:     estimate.proceeds,
+		SellFee:      estimate.sellFee,
+		Dust:         estimate.dust,
+		NetProfit:    estimate.net,
+		BreakEven:    estimate.breakEven,
+		Return:       estimate.net / estimate.spent,
+		info:         info,
+		filters:      filters,
+		spent:        estimate.spent,
+	}
+}
+
+func (r *estimateResult) Print(w io.Writer) {
+	info, base, quote := r.info, color.LightBlue.Render(r.info.BaseAsset), color.LightBlue.Render(r.info.QuoteAsset)
+	if r.Warning != "" {
+		fmt.Fprintln(w, color.Warn.Render(r.Warning))
+	}
+	fmt.Fprintf(w, "%s:      %0.4f%%\n", color.LightGreen.Render("Fee Rate"), r.FeeRate*100)
+	fmt.Fprintf(w, "%s:      %s %s at %s\n", color.LightGreen.Render("Quantity"), r.filters.formatQuantity(r.Quantity), base, formatQuotePrice(info, r.BuyPrice))
+	fmt.Fprintf(w, "%s:          %s %s\n", color.LightGreen.Render("Cost"), formatQuotePrice(info, r.Cost), quote)
+	fmt.Fprintf(w, "%s:       %s %s\n", color.LightGreen.Render("Buy Fee"), formatQuotePrice(info, r.BuyFee), quote)
+	fmt.Fprintf(w, "%s:      %s %s at %s\n", color.LightGreen.Render("Sell Qty"), r.filters.formatQuantity(r.SellQuantity), base, formatQuotePrice(info, r.SellPrice))
+	fmt.Fprintf(w, "%s:      %s %s\n", color.LightGreen.Render("Proceeds"), formatQuotePrice(info, r.Proceeds), quote)
+	fmt.Fprintf(w, "%s:      %s %s\n", color.LightGreen.Render("Sell Fee"), formatQuotePrice(info, r.SellFee), quote)
+	if r.Dust > 0 {
+		fmt.Fprintf(w, "%s:          %s %s\n", color.LightGreen.Render("Dust"), r.filters.formatQuantity(r.Dust), base)
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "%s:    %s\n", color.LightGreen.Render("Net Profit"), formatGain(info, r.spent+r.NetProfit, r.spent))
+	fmt.Fprintf(w, "%s:    %s %s\n", color.LightGreen.Render("Break Even"), formatQuotePrice(info, r.BreakEven), quote)
+	fmt.Fprintf(w, "%s:           %0.2f%%\n", color.LightGreen.Render("ROI"), r.Return*100)
+}
+
+func (r *estimateResult) Records() [][]string {
+	return [][]string{
+		{"symbol", "fee_rate", "quantity", "buy_price", "cost", "buy_fee", "sell_quantity", "sell_price", "proceeds", "sell_fee", "dust", "net_profit", "break_even", "return", "warning"},
+		{
+			r.Symbol,
+			output.FormatFloat(r.FeeRate),
+			output.FormatFloat(r.Quantity),
+			output.FormatFloat(r.BuyPrice),
+			output.FormatFloat(r.Cost),
+			output.FormatFloat(r.BuyFee),
+			output.FormatFloat(r.SellQuantity),
+			output.FormatFloat(r.SellPrice),
+			output.FormatFloat(r.Proceeds),
+			output.FormatFloat(r.SellFee),
+			output.FormatFloat(r.Dust),
+			output.FormatFloat(r.NetProfit),
+			output.FormatFloat(r.BreakEven),
+			output.FormatFloat(r.Return),
+			r.Warning,
+		},
+	}
+}
+
+// profitResult is the result of profit. The gains compare the value at the future price with the current value and
+// with the cost basis. The cost basis fields are empty if the symbol has no trades.
+type profitResult struct {
+	Symbol       string   `json:"symbol" yaml:"symbol"`
+	Balance      float64  `json:"balance" yaml:"balance"`
+	Price        float64  `json:"price" yaml:"price"`
+	FutureValue  float64  `json:"futureValue" yaml:"futureValue"`
+	CurrentPrice float64  `json:"currentPrice" yaml:"currentPrice"`
+	CurrentValue float64  `json:"currentValue" yaml:"currentValue"`
+	Gain         float64  `json:"gain" yaml:"gain"`
+	AverageCost  *float64 `json:"averageCost" yaml:"averageCost"`
+	CostBasis    *float64 `json:"costBasis" yaml:"costBasis"`
+	CostGain     *float64 `json:"costGain" yaml:"costGain"`
+
+	info binance.Symbol
+}
+
+func newProfitResult(info binance.Symbol, shares, price, currentPrice, averageCost float64) *profitResult {
+	result := &profitResult{
+		Symbol:       info.Symbol,
+		Balance:      shares,
+		Price:        price,
+		FutureValue:  shares * price,
+		CurrentPrice: currentPrice,
+		CurrentValue: shares * currentPrice,
+		info:         info,
+	}
+	result.Gain = result.FutureValue - result.CurrentValue
+
+	if averageCost > 0 {
+		costBasis := shares * averageCost
+		costGain := result.FutureValue - costBasis
+		result.AverageCost, result.CostBasis, result.CostGain = &averageCost, &costBasis, &costGain
+	}
+	return result
+}
+
+func (r *profitResult) Print(w io.Writer) {
+	info, base, quote := r.info, color.LightBlue.Render(r.info.BaseAsset), color.LightBlue.Render(r.info.QuoteAsset)
+	fmt.Fprintf(w, "%s:        %s %s\n", color.LightGreen.Render("Balance"), formatBasePrice(info, r.Balance), base)
+	fmt.Fprintf(w, "%s:   %s %s\n", color.LightGreen.Render("Future Value"), formatQuotePrice(info, r.FutureValue), quote)
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "%s:  %s %s\n", color.LightGreen.Render("Current Price"), formatQuotePrice(info, r.CurrentPrice), quote)
+	fmt.Fprintf(w, "%s:  %s %s\n", color.LightGreen.Render("Current Value"), formatQuotePrice(info, r.CurrentValue), quote)
+	fmt.Fprintf(w, "%s:           %s\n", color.LightGreen.Render("Gain"), formatGain(info, r.FutureValue, r.CurrentValue))
+	fmt.Fprintln(w)
+
+	if r.AverageCost == nil {
+		fmt.Fprintf(w, "%s:   %s\n", color.LightGreen.Render("Average Cost"), color.Gray.Render("no trades for "+r.Symbol))
+		return
+	}
+	fmt.Fprintf(w, "%s:   %s %s\n", color.LightGreen.Render("Average Cost"), formatQuotePrice(info, *r.AverageCost), quote)
+	fmt.Fprintf(w, "%s:     %s %s\n", color.LightGreen.Render("Cost Basis"), formatQuotePrice(info, *r.CostBasis), quote)
+	fmt.Fprintf(w, "%s:           %s\n", color.LightGreen.Render("Gain"), formatGain(info, r.FutureValue, *r.CostBasis))
+}
+
+func (r *profitResult) Records() [][]string {
+	optional := func(value *float64) string {
+		if value == nil {
+			return ""
+		}
+		return output.FormatFloat(*value)
+	}
+
+	return [][]string{
+		{"symbol", "balance", "price", "future_value", "current_price", "current_value", "gain", "average_cost", "cost_basis", "cost_gain"},
+		{
+			r.Symbol,
+			output.FormatFloat(r.Balance),
+			output.FormatFloat(r.Price),
+			output.FormatFloat(r.FutureValue),
+			output.FormatFloat(r.CurrentPrice),
+			output.FormatFloat(r.CurrentValue),
+			output.FormatFloat(r.Gain),
+			optional(r.AverageCost),
+			optional(r.CostBasis),
+			optional(r.CostGain),
+		},
+	}
+}
+
+// indicatorKind decides how an indicator is printed.
+type indicatorKind int
+
+const (
+	// priceIndicator is a price shown with the distance of the close from it.
+	priceIndicator indicatorKind = iota
+
+	// rsiIndicator is colored when overbought or oversold.
+	rsiIndicator
+
+	// valueIndicator is a plain value in the quote asset.
+	valueIndicator
+
+	// changeIndicator is colored by its sign.
+	changeIndicator
+
+	// rangeIndicator is a price range shown as a share of the close.
+	rangeIndicator
+)
+
+// symbolIndicator is the latest value of an indicator. The value is empty when there are not enough candles.
+type symbolIndicator struct {
+	Group string   `json:"group" yaml:"group"`
+	Name  string   `json:"name" yaml:"name"`
+	Value *float64 `json:"value" yaml:"value"`
+
+	kind indicatorKind
+}
+
+func (i symbolIndicator) value() float64 {
+	if i.Value == nil {
+		return math.NaN()
+	}
+	return *i.Value
+}
+
+// indicatorsResult is the result of indicators.
+type indicatorsResult struct {
+	Symbol     string            `json:"symbol" yaml:"symbol"`
+	Interval   string            `json:"interval" yaml:"interval"`
+	Candles    int               `json:"candles" yaml:"candles"`
+	Close      float64           `json:"close" yaml:"close"`
+	Indicators []symbolIndicator `json:"indicators" yaml:"indicators"`
+
+	info binance.Symbol
+}
+
+// add appends an indicator. NaN values are left empty because they cannot be encoded.
+func (r *indicatorsResult) add(group, name string, kind indicatorKind, value float64) {
+	indicator := symbolIndicator{Group: group, Name: name, kind: kind}
+	if !math.IsNaN(value) {
+		indicator.Value = &value
+	}
+	r.Indicators = append(r.Indicators, indicator)
+}
+
+func (r *indicatorsResult) Print(w io.Writer) {
+	info := r.info
+	fmt.Fprintf(w, "\n%s:   %s\n", color.LightGreen.Render("Symbol"), r.Symbol)
+	fmt.Fprintf(w, "%s: %s (%d candles)\n", color.LightGreen.Render("Interval"), r.Interval, r.Candles)
+	fmt.Fprintf(w, "%s:    %s %s\n", color.LightGreen.Render("Close"), formatQuotePrice(info, r.Close), color.LightBlue.Render(info.QuoteAsset))
+
+	var group string
+	for _, indicator := range r.Indicators {
+		if indicator.Group != group {
+			fmt.Fprintln(w, color.LightWhite.Render("\n"+indicator.Group+":"))
+			group = indicator.Group
+		}
+
+		value := indicator.value()
+		name := color.LightYellow.Render(indicator.Name)
+		switch indicator.kind {
+		case priceIndicator:
+			printIndicator(w, info, indicator.Name, value, r.Close)
+		case rsiIndicator:
+			fmt.Fprintf(w, "- %s: %s\n", name, formatRSI(value))
+		case changeIndicator:
+			fmt.Fprintf(w, "- %s: %s\n", name, formatChange(fmt.Sprint(value), formatIndicatorValue(info, value)))
+		case rangeIndicator:
+			fmt.Fprintf(w, "- %s: %s (%0.2f%% of close)\n", name, formatIndicatorValue(info, value), value/r.Close*100)
+		default:
+			fmt.Fprintf(w, "- %s: %s\n", name, formatIndicatorValue(info, value))
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+func (r *indicatorsResult) Records() [][]string {
+	records := [][]string{{"group", "name", "value"}}
+	for _, indicator := range r.Indicators {
+		value := ""
+		if indicator.Value != nil {
+			value = output.FormatFloat(*indicator.Value)
+		}
+		records = append(records, []string{indicator.Group, indicator.Name, value})
+	}
+	return records
+}
+
+// backtestTrade is a completed round trip of a backtest. The return is a fraction.
+type backtestTrade struct {
+	EntryTime  time.Time `json:"entryTime" yaml:"entryTime"`
+	EntryPrice float64   `json:"entryPrice" yaml:"entryPrice"`
+	ExitTime   time.Time `json:"exitTime" yaml:"exitTime"`
+	ExitPrice  float64   `json:"exitPrice" yaml:"exitPrice"`
+	Quantity   float64   `json:"quantity" yaml:"quantity"`
+	Fees       float64   `json:"fees" yaml:"fees"`
+	Profit     float64   `json:"profit" yaml:"profit"`
+	Return     float64   `json:"return" yaml:"return"`
+}
+
+// backtestResult is the result of backtest. The period runs from the open of the first candle to the open of the
+// last and the fee, returns, drawdown and win rate are fractions. The records are the trades; the equity curve is
+// written with --equity.
+type backtestResult struct {
+	Symbol      string          `json:"symbol" yaml:"symbol"`
+	Interval    string          `json:"interval" yaml:"interval"`
+	Strategy    string          `json:"strategy" yaml:"strategy"`
+	Candles     int             `json:"candles" yaml:"candles"`
+	From        time.Time       `json:"from" yaml:"from"`
+	To          time.Time       `json:"to" yaml:"to"`
+	Fee         float64         `json:"fee" yaml:"fee"`
+	Capital     float64         `json:"capital" yaml:"capital"`
+	FinalEquity float64         `json:"finalEquity" yaml:"finalEquity"`
+	TotalReturn float64         `json:"totalReturn" yaml:"totalReturn"`
+	BuyAndHold  float64         `json:"buyAndHold" yaml:"buyAndHold"`
+	MaxDrawdown float64         `json:"maxDrawdown" yaml:"maxDrawdown"`
+	WinRate     float64         `json:"winRate" yaml:"winRate"`
+	Sharpe      float64         `json:"sharpe" yaml:"sharpe"`
+	Fees        float64         `json:"fees" yaml:"fees"`
+	Trades      []backtestTrade `json:"trades" yaml:"trades"`
+
+	equity []backtest.EquityPoint
+}
+
+func newBacktestResult(symbol, interval string, strategy backtest.Strategy, fee float64, candles []backtest.Candle, result *backtest.Result) *backtestResult {
+	first, last := candles[0], candles[len(candles)-1]
+	r := &backtestResult{
+		Symbol:      symbol,
+		Interval:    interval,
+		Strategy:    strategy.Name(),
+		Candles:     len(candles),
+		From:        first.Time.UTC(),
+		To:          last.Time.UTC(),
+		Fee:         fee,
+		Capital:     result.Capital,
+		FinalEquity: result.FinalEquity,
+		TotalReturn: result.TotalReturn,
+		BuyAndHold:  (last.Close - first.Open) / first.Open,
+		MaxDrawdown: result.MaxDrawdown,
+		WinRate:     result.WinRate,
+		Sharpe:      result.Sharpe,
+		Fees:        result.Fees,
+		Trades:      make([]backtestTrade, 0, len(result.Trades)),
+		equity:      result.Equity,
+	}
+	for _, trade := range result.Trades {
+		r.Trades = append(r.Trades, backtestTrade{
+			EntryTime:  trade.EntryTime.UTC(),
+			EntryPrice: trade.EntryPrice,
+			ExitTime:   trade.ExitTime.UTC(),
+			ExitPrice:  trade.ExitPrice,
+			Quantity:   trade.Quantity,
+			Fees:       trade.Fees,
+			Profit:     trade.Profit,
+			Return:     trade.Return,
+		})
+	}
+	return r
+}
+
+func (r *backtestResult) Print(w io.Writer) {
+	timeFormat := getKlineTimeFormat(r.Interval)
+	if len(r.Trades) > 0 {
+		table := tablewriter.NewWriter(w)
+		table.SetHeader([]string{"Entry", "Entry Price", "Exit", "Exit Price", "Quantity", "Fees", "Profit", "Return"})
+		for _, trade := range r.Trades {
+			table.Append([]string{
+				trade.EntryTime.Local().Format(timeFormat),
+				formatAmount(trade.EntryPrice),
+				trade.ExitTime.Local().Format(timeFormat),
+				formatAmount(trade.ExitPrice),
+				fmt.Sprintf("%0.8f", trade.Quantity),
+				fmt.Sprintf("%0.4f", trade.Fees),
+				formatChange(fmt.Sprint(trade.Profit), fmt.Sprintf("%0.4f", trade.Profit)),
+				formatChange(fmt.Sprint(trade.Return), fmt.Sprintf("%0.2f%%", trade.Return*100)),
+			})
+		}
+		table.Render() // Send output
+	}
+
+	fmt.Fprintf(w, "\n%s:     %s\n", color.LightGreen.Render("Strategy"), r.Strategy)
+	fmt.Fprintf(w, "%s:       %s %s (%d candles)\n", color.LightGreen.Render("Symbol"), r.Symbol, r.Interval, r.Candles)
+	fmt.Fprintf(w, "%s:       %s to %s\n", color.LightGreen.Render("Period"), r.From.Local().Format(timeFormat), r.To.Local().Format(timeFormat))
+	fmt.Fprintf(w, "%s:          %0.4f%%\n", color.LightGreen.Render("Fee"), r.Fee*100)
+	fmt.Fprintf(w, "%s:      %0.4f\n", color.LightGreen.Render("Capital"), r.Capital)
+	fmt.Fprintf(w, "%s: %0.4f\n", color.LightGreen.Render("Final Equity"), r.FinalEquity)
+	fmt.Fprintf(w, "%s: %s\n", color.LightGreen.Render("Total Return"), formatChange(fmt.Sprint(r.TotalReturn), fmt.Sprintf("%0.2f%%", r.TotalReturn*100)))
+	fmt.Fprintf(w, "%s:  %s\n", color.LightGreen.Render("Buy & Hold"), formatChange(fmt.Sprint(r.BuyAndHold), fmt.Sprintf("%0.2f%%", r.BuyAndHold*100)))
+	fmt.Fprintf(w, "%s: %0.2f%%\n", color.LightGreen.Render("Max Drawdown"), r.MaxDrawdown*100)
+	fmt.Fprintf(w, "%s:       %d\n", color.LightGreen.Render("Trades"), len(r.Trades))
+	fmt.Fprintf(w, "%s:     %0.2f%%\n", color.LightGreen.Render("Win Rate"), r.WinRate*100)
+	fmt.Fprintf(w, "%s:       %0.2f\n", color.LightGreen.Render("Sharpe"), r.Sharpe)
+	fmt.Fprintf(w, "%s:   %0.4f\n", color.LightGreen.Render("Total Fees"), r.Fees)
+
+	width, _ := getTerminalSize()
+	fmt.Fprintf(w, "\n%s:\n%s\n\n", color.LightGreen.Render("Equity Curve"), renderSparkline(r.equity, width-2))
+}
+
+func (r *backtestResult) Records() [][]string {
+	records := [][]string{{"entry_time", "entry_price", "exit_time", "exit_price", "quantity", "fees", "profit", "return"}}
+	for _, trade := range r.Trades {
+		records = append(records, []string{
+			trade.EntryTime.Format(time.RFC3339),
+			output.FormatFloat(trade.EntryPrice),
+			trade.ExitTime.Format(time.RFC3339),
+			output.FormatFloat(trade.ExitPrice),
+			output.FormatFloat(trade.Quantity),
+			output.FormatFloat(trade.Fees),
+			output.FormatFloat(trade.Profit),
+			output.FormatFloat(trade.Return),
+		})
+	}
+	return records
+}
+
+// arbitrageTriangle is a cycle of three markets. The implied and direct prices are of the first asset in the last
+// asset before returning to the first, and the depth is in the first asset. The return is a fraction.
+type arbitrageTriangle struct {
+	Route      []string `json:"route" yaml:"route"`
+	Markets    []string `json:"markets" yaml:"markets"`
+	PriceAsset string   `json:"priceAsset" yaml:"priceAsset"`
+	Implied    float64  `json:"implied" yaml:"implied"`
+	Direct     float64  `json:"direct" yaml:"direct"`
+	Return     float64  `json:"return" yaml:"return"`
+	Depth      float64  `json:"depth" yaml:"depth"`
+
+	steps []conversionStep
+}
+
+// arbitrageResult is the result of arbitrage-scan. The fee and minimum return are fractions.
+type arbitrageResult struct {
+	Fee       float64             `json:"fee" yaml:"fee"`
+	Minimum   float64             `json:"minimum" yaml:"minimum"`
+	Triangles []arbitrageTriangle `json:"triangles" yaml:"triangles"`
+}
+
+func newArbitrageResult(fee, minimum float64, triangles []triangle) *arbitrageResult {
+	result := &arbitrageResult{Fee: fee, Minimum: minimum, Triangles: make([]arbitrageTriangle, 0, len(triangles))}
+	for _, t := range triangles {
+		route := []string{t.steps[0].from}
+		markets := make([]string, 0, len(t.steps))
+		for _, step := range t.steps {
+			route = append(route, step.to)
+			markets = append(markets, step.symbol)
+		}
+
+		result.Triangles = append(result.Triangles, arbitrageTriangle{
+			Route:      route,
+			Markets:    markets,
+			PriceAsset: t.steps[2].from,
+			Implied:    t.implied,
+			Direct:     t.direct,
+			Return:     t.gain,
+			Depth:      t.depth,
+			steps:      t.steps,
+		})
+	}
+	return result
+}
+
+func (r *arbitrageResult) Print(w io.Writer) {
+	fmt.Fprintf(w, "\n%s: %0.4f%% per leg\n\n", color.LightGreen.Render("Fee"), r.Fee*100)
+	if len(r.Triangles) == 0 {
+		fmt.Fprintf(w, "No triangles return at least %0.2f%%.\n", r.Minimum*100)
+		return
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Route", "Markets", "Implied", "Direct", "Return", "Depth"})
+	for _, t := range r.Triangles {
+		table.Append([]string{
+			formatRoute(t.Route[0], t.steps),
+			strings.Join(t.Markets, ", "),
+			fmt.Sprintf("%0.8f %s", t.Implied, t.PriceAsset),
+			fmt.Sprintf("%0.8f %s", t.Direct, t.PriceAsset),
+			formatChange(fmt.Sprint(t.Return), fmt.Sprintf("%0.4f%%", t.Return*100)),
+			fmt.Sprintf("%s %s", formatAmount(t.Depth), t.Route[0]),
+		})
+	}
+	table.Render() // Send output
+}
+
+func (r *arbitrageResult) Records() [][]string {
+	records := [][]string{{"route", "markets", "price_asset", "implied", "direct", "return", "depth"}}
+	for _, t := range r.Triangles {
+		records = append(records, []string{
+			strings.Join(t.Route, ","),
+			strings.Join(t.Markets, ","),
+			t.PriceAsset,
+			output.FormatFloat(t.Implied),
+			output.FormatFloat(t.Direct),
+			output.FormatFloat(t.Return),
+			output.FormatFloat(t.Depth),
+		})
+	}
+	return records
+}
+
+// marketTradesResult is the result of the market trade commands.
+type marketTradesResult struct {
+	Trades []marketTrade `json:"trades" yaml:"trades"`
+}
+
+type marketTrade struct {
+	ID       int64     `json:"id" yaml:"id"`
+	Time     time.Time `json:"time" yaml:"time"`
+	Price    float64   `json:"price" yaml:"price"`
+	Quantity float64   `json:"quantity" yaml:"quantity"`
+
+	price, quantity string
+}
+
+func newMarketTradesResult(trades []*binance.Trade) (*marketTradesResult, error) {
+	result := &marketTradesResult{Trades: make([]marketTrade, 0, len(trades))}
+	for _, trade := range trades {
+		price, err := strconv.ParseFloat(trade.Price, 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse price: %s", trade.Price)
+		}
+		quantity, err := strconv.ParseFloat(trade.Quantity, 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse quantity: %s", trade.Quantity)
+		}
+
+		result.Trades = append(result.Trades, marketTrade{
+			ID:       trade.ID,
+			Time:     fromMillis(trade.Time).UTC(),
+			Price:    price,
+			Quantity: quantity,
+			price:    trade.Price,
+			quantity: trade.Quantity,
+		})
+	}
+	return result, nil
+}
+
+func (r *marketTradesResult) Print(w io.Writer) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"ID", "Timestamp", "Price", "Quantity"})
+	for _, trade := range r.Trades {
+		table.Append([]string{
+			strconv.FormatInt(trade.ID, 10),
+			trade.Time.Local().Format("2006-01-02T15:04:05"),
+			trade.price,
+			trade.quantity,
+		})
+	}
+	table.Render() // Send output
+}
+
+func (r *marketTradesResult) Records() [][]string {
+	records := [][]string{{"id", "time", "price", "quantity"}}
+	for _, trade := range r.Trades {
+		records = append(records, []string{
+			strconv.FormatInt(trade.ID, 10),
+			trade.Time.Format(time.RFC3339),
+			output.FormatFloat(trade.Price),
+			output.FormatFloat(trade.Quantity),
+		})
+	}
+	return records
+}
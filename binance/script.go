@@ -0,0 +1,177 @@
+package binance
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	binance "github.com/adshao/go-binance/v2"
+	"github.com/eliquious/console"
+)
+
+// scriptOrdersKey enables live order placement from scripts. Paper trading orders are always allowed.
+const scriptOrdersKey = "binance.script_orders"
+
+// ScriptAPI is the binance object of the JS interpreter. Methods are exposed with a lower case first letter and
+// results use their JSON field names.
+type ScriptAPI struct {
+	env     *console.Environment
+	client  *binance.Client
+	traders *traders
+	symbols []binance.Symbol
+}
+
+// scriptKline is a kline with times in milliseconds so scripts can pass them to Date.
+type scriptKline struct {
+	OpenTime  int64   `json:"openTime"`
+	CloseTime int64   `json:"closeTime"`
+	Open      float64 `json:"open"`
+	High      float64 `json:"high"`
+	Low       float64 `json:"low"`
+	Close     float64 `json:"close"`
+	Volume    float64 `json:"volume"`
+}
+
+// Price returns the last price of a symbol.
+func (api *ScriptAPI) Price(symbol string) (float64, error) {
+	symbol = strings.ToUpper(symbol)
+	prices, err := api.client.NewListPricesService().Symbol(symbol).Do(context.Background())
+	if err != nil {
+		return 0, err
+	} else if len(prices) == 0 {
+		return 0, errors.New("unknown symbol: " + symbol)
+	}
+	return strconv.ParseFloat(prices[0].Price, 64)
+}
+
+// Prices returns the last price of every symbol.
+func (api *ScriptAPI) Prices() (map[string]float64, error) {
+	currentPrices, err := getCurrentPrices(api.client)
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make(map[string]float64, len(currentPrices))
+	for symbol, text := range currentPrices {
+		if price, err := strconv.ParseFloat(text, 64); err == nil {
+			prices[symbol] = price
+		}
+	}
+	return prices, nil
+}
+
+// Balances returns the non-zero balances of the account, or of the paper account if binance.paper is set.
+func (api *ScriptAPI) Balances() ([]assetBalance, error) {
+	t, err := api.traders.get(api.env, nil)
+	if err != nil {
+		return nil, err
+	}
+	account, err := t.getAccount()
+	if err != nil {
+		return nil, err
+	}
+
+	balances := newBalanceResult(isPaper(t), account.Balances).Balances
+	sort.Slice(balances, func(i, j int) bool {
+		return balances[i].Asset < balances[j].Asset
+	})
+	return balances, nil
+}
+
+// Klines returns the latest klines of a symbol. The interval defaults to 1d and the limit to 100.
+func (api *ScriptAPI) Klines(symbol, interval string, limit int) ([]scriptKline, error) {
+	if interval == "" {
+		interval = "1d"
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+
+	candles, err := getCandles(api.client, getMarketStore(api.env.Configuration), strings.ToUpper(symbol), interval, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	klines := make([]scriptKline, 0, len(candles))
+	for _, candle := range candles {
+		klines = append(klines, scriptKline{
+			OpenTime:  toMillis(candle.openTime),
+			CloseTime: toMillis(candle.closeTime),
+			Open:      candle.open,
+			High:      candle.high,
+			Low:       candle.low,
+			Close:     candle.close,
+			Volume:    candle.volume,
+		})
+	}
+	return klines, nil
+}
+
+// Depth returns the order book of a symbol. The levels default to 10.
+func (api *ScriptAPI) Depth(symbol string, levels int) (*depthResult, error) {
+	if levels <= 0 {
+		levels = 10
+	}
+
+	symbol = strings.ToUpper(symbol)
+	resp, err := api.client.NewDepthService().Symbol(symbol).Limit(getDepthLimit(levels)).Do(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return newDepthResult(symbol, resp, levels)
+}
+
+// Buy places a limit buy order.
+func (api *ScriptAPI) Buy(symbol string, quantity, price float64) (*binance.CreateOrderResponse, error) {
+	return api.placeOrder(binance.SideTypeBuy, symbol, quantity, price)
+}
+
+// Sell places a limit sell order.
+func (api *ScriptAPI) Sell(symbol string, quantity, price float64) (*binance.CreateOrderResponse, error) {
+	return api.placeOrder(binance.SideTypeSell, symbol, quantity, price)
+}
+
+// Cancel cancels an open order. Like placing orders it needs binance.script_orders unless trading on paper.
+func (api *ScriptAPI) Cancel(symbol string, orderID int64) (*binance.CancelOrderResponse, error) {
+	t, err := api.getOrderTrader()
+	if err != nil {
+		return nil, err
+	}
+	return t.cancelOrder(strings.ToUpper(symbol), orderID)
+}
+
+// placeOrder validates the order against the symbol filters and sends it.
+func (api *ScriptAPI) placeOrder(side binance.SideType, symbol string, quantity, price float64) (*binance.CreateOrderResponse, error) {
+	info, err := getSymbolInfo(api.symbols, strings.ToUpper(symbol))
+	if err != nil {
+		return nil, err
+	}
+	filters, err := getSymbolFilters(info)
+	if err != nil {
+		return nil, err
+	}
+	if err := filters.validateOrder(price, quantity); err != nil {
+		return nil, err
+	}
+
+	t, err := api.getOrderTrader()
+	if err != nil {
+		return nil, err
+	}
+	return t.createLimitOrder(info, side, filters.formatQuantity(quantity), filters.formatPrice(price))
+}
+
+// getOrderTrader returns the trader for placing and cancelling orders. Scripts do not ask for confirmation, so the
+// exchange is only used if binance.script_orders is set.
+func (api *ScriptAPI) getOrderTrader() (trader, error) {
+	t, err := api.traders.get(api.env, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !isPaper(t) && !api.env.Configuration.GetBool(scriptOrdersKey) {
+		return nil, errors.New("orders from scripts are disabled: set " + scriptOrdersKey + " to true or binance.paper to trade on paper")
+	}
+	return t, nil
+}
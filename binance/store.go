@@ -0,0 +1,627 @@
+package binance
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	binance "github.com/adshao/go-binance/v2"
+	"github.com/eliquious/console"
+	"github.com/gookit/color"
+	"github.com/spf13/viper"
+)
+
+// maxTradeSyncLimit is the default maximum number of trades downloaded by one sync.
+const maxTradeSyncLimit = 100000
+
+var klineHeader = []string{"open_time", "open", "high", "low", "close", "volume", "close_time", "quote_volume", "trades", "taker_buy_volume", "taker_buy_quote_volume"}
+
+var tradeHeader = []string{"id", "price", "quantity", "time", "is_buyer_maker", "is_best_match"}
+
+// errStopReading is returned by a row handler to stop reading a CSV file early.
+var errStopReading = errors.New("stop reading")
+
+// marketStore keeps downloaded market data in CSV files under the data directory so historical commands work offline
+// and only new data is downloaded. Klines are stored per symbol and interval and trades per symbol.
+type marketStore struct {
+	dir string
+}
+
+// getMarketStore returns the store in the `binance.data_dir` directory.
+func getMarketStore(conf *viper.Viper) *marketStore {
+	return &marketStore{dir: conf.GetString("binance.data_dir")}
+}
+
+func (s *marketStore) klinesPath(symbol, interval string) string {
+	// 1m and 1M would be the same file on case insensitive file systems
+	if interval == "1M" {
+		interval = "1mo"
+	}
+	return filepath.Join(s.dir, "klines", symbol, interval+".csv")
+}
+
+func (s *marketStore) tradesPath(symbol string) string {
+	return filepath.Join(s.dir, "trades", symbol+".csv")
+}
+
+// readCSV reads the rows of a CSV file without the header until the handler returns errStopReading. A missing file
+// has no rows.
+func readCSV(path string, handler func(row []string) error) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	if _, err := reader.Read(); err == io.EOF {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("%s: %s", path, err)
+	}
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%s: %s", path, err)
+		}
+		if err := handler(row); err == errStopReading {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%s: %s", path, err)
+		}
+	}
+}
+
+// writeCSV replaces a CSV file. The rows are written to a temporary file first so a failed write never loses data.
+func writeCSV(path string, header []string, rows [][]string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path + ".tmp")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write(header)
+	writer.WriteAll(rows)
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// appendCSV adds rows to the end of a CSV file, creating it with the header if needed.
+func appendCSV(path string, header []string, rows [][]string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	_, err := os.Stat(path)
+	exists := err == nil
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if !exists {
+		writer.Write(header)
+	}
+	writer.WriteAll(rows)
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// loadKlines reads the stored klines of a symbol sorted by open time.
+func (s *marketStore) loadKlines(symbol, interval string) ([]*binance.Kline, error) {
+	var klines []*binance.Kline
+	err := readCSV(s.klinesPath(symbol, interval), func(row []string) error {
+		if len(row) != len(klineHeader) {
+			return errors.New("invalid kline row")
+		}
+
+		openTime, err := strconv.ParseInt(row[0], 10, 64)
+		if err != nil {
+			return err
+		}
+		closeTime, err := strconv.ParseInt(row[6], 10, 64)
+		if err != nil {
+			return err
+		}
+		trades, err := strconv.ParseInt(row[8], 10, 64)
+		if err != nil {
+			return err
+		}
+
+		klines = append(klines, &binance.Kline{
+			OpenTime:                 openTime,
+			Open:                     row[1],
+			High:                     row[2],
+			Low:                      row[3],
+			Close:                    row[4],
+			Volume:                   row[5],
+			CloseTime:                closeTime,
+			QuoteAssetVolume:         row[7],
+			TradeNum:                 trades,
+			TakerBuyBaseAssetVolume:  row[9],
+			TakerBuyQuoteAssetVolume: row[10],
+		})
+		return nil
+	})
+	return klines, err
+}
+
+// saveKlines replaces the stored klines of a symbol.
+func (s *marketStore) saveKlines(symbol, interval string, klines []*binance.Kline) error {
+	rows := make([][]string, 0, len(klines))
+	for _, kline := range klines {
+		rows = append(rows, []string{
+			strconv.FormatInt(kline.OpenTime, 10),
+			kline.Open,
+			kline.High,
+			kline.Low,
+			kline.Close,
+			kline.Volume,
+			strconv.FormatInt(kline.CloseTime, 10),
+			kline.QuoteAssetVolume,
+			strconv.FormatInt(kline.TradeNum, 10),
+			kline.TakerBuyBaseAssetVolume,
+			kline.TakerBuyQuoteAssetVolume,
+		})
+	}
+	return writeCSV(s.klinesPath(symbol, interval), klineHeader, rows)
+}
+
+// syncKlines downloads the klines missing from the store: before the first stored kline if the start is earlier and
+// everything after the last one. Without a start time the first sync begins at the first kline of the symbol. Only
+// closed klines are stored. It returns the number of klines added.
+//
+// Gaps between stored klines are not downloaded again. The store is only written after a complete download, so they
+// are times the exchange has no klines for, such as maintenance, and would be requested on every sync.
+func (s *marketStore) syncKlines(client *binance.Client, symbol, interval string, start time.Time) (int, error) {
+	stored, err := s.loadKlines(symbol, interval)
+	if err != nil {
+		return 0, err
+	}
+
+	// ranges of open times to download
+	type timeRange struct{ from, to time.Time }
+	var missing []timeRange
+	now := time.Now()
+	if len(stored) == 0 {
+		// the first kline of the symbol is returned when starting from the epoch
+		if start.IsZero() {
+			start = time.Unix(0, 0)
+		}
+		missing = append(missing, timeRange{start, now})
+	} else {
+		if !start.IsZero() && toMillis(start) < stored[0].OpenTime {
+			missing = append(missing, timeRange{start, fromMillis(stored[0].OpenTime)})
+		}
+		missing = append(missing, timeRange{fromMillis(stored[len(stored)-1].CloseTime + 1), now})
+	}
+
+	klines := make(map[int64]*binance.Kline, len(stored))
+	for _, kline := range stored {
+		klines[kline.OpenTime] = kline
+	}
+
+	var added int
+	for _, r := range missing {
+		page, err := fetchKlines(client, symbol, interval, r.from, r.to, maxKlineLimit)
+		if err != nil {
+			return 0, err
+		}
+		for _, kline := range page {
+			if _, ok := klines[kline.OpenTime]; !ok && kline.CloseTime < toMillis(now) {
+				klines[kline.OpenTime] = kline
+				added++
+			}
+		}
+	}
+	if added == 0 {
+		return 0, nil
+	}
+
+	merged := make([]*binance.Kline, 0, len(klines))
+	for _, kline := range klines {
+		merged = append(merged, kline)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].OpenTime < merged[j].OpenTime
+	})
+	return added, s.saveKlines(symbol, interval, merged)
+}
+
+// listKlines reads klines from the store and only downloads the ones after the last stored kline. Requests the store
+// cannot answer are downloaded in full. A nil store always downloads.
+func listKlines(client *binance.Client, store *marketStore, symbol, interval string, start, end time.Time, limit int) ([]*binance.Kline, error) {
+	if store == nil {
+		return fetchKlines(client, symbol, interval, start, end, limit)
+	}
+
+	stored, err := store.loadKlines(symbol, interval)
+	if err != nil {
+		color.Warn.Printf("ignoring the kline store: %s\n", err)
+		return fetchKlines(client, symbol, interval, start, end, limit)
+	}
+	if len(stored) == 0 || (!start.IsZero() && toMillis(start) < stored[0].OpenTime) {
+		return fetchKlines(client, symbol, interval, start, end, limit)
+	}
+
+	var klines []*binance.Kline
+	for _, kline := range stored {
+		if !start.IsZero() && kline.OpenTime < toMillis(start) {
+			continue
+		}
+		if !end.IsZero() && kline.OpenTime >= toMillis(end) {
+			break
+		}
+		klines = append(klines, kline)
+	}
+
+	// download the klines after the store
+	last := stored[len(stored)-1]
+	if end.IsZero() || toMillis(end) > last.CloseTime+1 {
+		tail, err := fetchKlines(client, symbol, interval, fromMillis(last.CloseTime+1), end, maxKlineLimit)
+		if err != nil {
+			return nil, err
+		}
+		klines = append(klines, tail...)
+	}
+
+	if start.IsZero() {
+		if len(klines) < limit {
+			return fetchKlines(client, symbol, interval, start, end, limit)
+		}
+		klines = klines[len(klines)-limit:]
+	}
+	return klines, nil
+}
+
+// loadTrades reads up to the limit of stored trades starting at the trade ID.
+func (s *marketStore) loadTrades(symbol string, fromID int64, limit int) ([]*binance.Trade, error) {
+	var trades []*binance.Trade
+	err := readCSV(s.tradesPath(symbol), func(row []string) error {
+		if len(row) != len(tradeHeader) {
+			return errors.New("invalid trade row")
+		}
+
+		id, err := strconv.ParseInt(row[0], 10, 64)
+		if err != nil {
+			return err
+		} else if id < fromID {
+			return nil
+		}
+		timestamp, err := strconv.ParseInt(row[3], 10, 64)
+		if err != nil {
+			return err
+		}
+
+		trades = append(trades, &binance.Trade{
+			ID:           id,
+			Price:        row[1],
+			Quantity:     row[2],
+			Time:         timestamp,
+			IsBuyerMaker: row[4] == "true",
+			IsBestMatch:  row[5] == "true",
+		})
+		if len(trades) >= limit {
+			return errStopReading
+		}
+		return nil
+	})
+	return trades, err
+}
+
+// lastTradeID returns the ID of the last stored trade. Only the end of the file is read.
+func (s *marketStore) lastTradeID(symbol string) (int64, bool, error) {
+	file, err := os.Open(s.tradesPath(symbol))
+	if os.IsNotExist(err) {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, false, err
+	}
+
+	// a row is far shorter than the tail
+	offset := info.Size() - 4096
+	if offset < 0 {
+		offset = 0
+	}
+	tail := make([]byte, info.Size()-offset)
+	if _, err := file.ReadAt(tail, offset); err != nil && err != io.EOF {
+		return 0, false, err
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(tail)), "\n")
+	last := lines[len(lines)-1]
+	if last == "" || strings.HasPrefix(last, tradeHeader[0]+",") {
+		return 0, false, nil
+	}
+	id, err := strconv.ParseInt(strings.SplitN(last, ",", 2)[0], 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("%s: invalid trade row", s.tradesPath(symbol))
+	}
+	return id, true, nil
+}
+
+func (s *marketStore) appendTrades(symbol string, trades []*binance.Trade) error {
+	rows := make([][]string, 0, len(trades))
+	for _, trade := range trades {
+		rows = append(rows, []string{
+			strconv.FormatInt(trade.ID, 10),
+			trade.Price,
+			trade.Quantity,
+			strconv.FormatInt(trade.Time, 10),
+			strconv.FormatBool(trade.IsBuyerMaker),
+			strconv.FormatBool(trade.IsBestMatch),
+		})
+	}
+	return appendCSV(s.tradesPath(symbol), tradeHeader, rows)
+}
+
+// syncTrades downloads market trades after the last stored trade. The first sync starts at the given time or at the
+// latest trade. It returns the number of trades added.
+func (s *marketStore) syncTrades(client *binance.Client, symbol string, start time.Time, limit int) (int, error) {
+	lastID, ok, err := s.lastTradeID(symbol)
+	if err != nil {
+		return 0, err
+	}
+
+	var fromID int64
+	if ok {
+		fromID = lastID + 1
+	} else if !start.IsZero() {
+		// aggregate trades can be searched by time and point at the first trade ID
+		agg, err := client.NewAggTradesService().
+			Symbol(symbol).
+			StartTime(toMillis(start)).
+			EndTime(toMillis(start.Add(time.Hour)) - 1).
+			Limit(1).
+			Do(context.Background())
+		if err != nil {
+			return 0, err
+		} else if len(agg) == 0 {
+			return 0, fmt.Errorf("no trades found in the hour after %s", start.Format(dateFormat))
+		}
+		fromID = agg[0].FirstTradeID
+	} else {
+		recent, err := client.NewRecentTradesService().Symbol(symbol).Limit(1).Do(context.Background())
+		if err != nil {
+			return 0, err
+		} else if len(recent) == 0 {
+			return 0, nil
+		}
+		fromID = recent[0].ID
+	}
+
+	var added int
+	for added < limit {
+		page, err := client.NewHistoricalTradesService().
+			Symbol(symbol).
+			FromID(fromID).
+			Limit(maxTradeHistoryLimit).
+			Do(context.Background())
+		if err != nil {
+			return added, err
+		}
+		if len(page) == 0 {
+			return added, nil
+		}
+
+		if err := s.appendTrades(symbol, page); err != nil {
+			return added, err
+		}
+		added += len(page)
+
+		if len(page) < maxTradeHistoryLimit {
+			return added, nil
+		}
+		fromID = page[len(page)-1].ID + 1
+	}
+	return added, nil
+}
+
+// fetchTrades downloads market trades starting at the trade ID. A negative trade ID downloads the latest trades.
+func fetchTrades(client *binance.Client, symbol string, fromID int64, limit int) ([]*binance.Trade, error) {
+	if fromID < 0 {
+		return client.NewRecentTradesService().Symbol(symbol).Limit(limit).Do(context.Background())
+	}
+	return client.NewHistoricalTradesService().Symbol(symbol).FromID(fromID).Limit(limit).Do(context.Background())
+}
+
+// listTrades reads market trades from the store and only downloads the trades after the last stored trade. A negative
+// trade ID lists the latest trades. Requests the store cannot answer are downloaded in full. A nil store always
+// downloads.
+func listTrades(client *binance.Client, store *marketStore, symbol string, fromID int64, limit int) ([]*binance.Trade, error) {
+	if store == nil {
+		return fetchTrades(client, symbol, fromID, limit)
+	}
+
+	lastID, ok, err := store.lastTradeID(symbol)
+	if err != nil {
+		color.Warn.Printf("ignoring the trade store: %s\n", err)
+		return fetchTrades(client, symbol, fromID, limit)
+	} else if !ok || fromID > lastID {
+		return fetchTrades(client, symbol, fromID, limit)
+	}
+
+	if fromID >= 0 {
+		stored, err := store.loadTrades(symbol, fromID, limit)
+		if err != nil {
+			color.Warn.Printf("ignoring the trade store: %s\n", err)
+			return fetchTrades(client, symbol, fromID, limit)
+		} else if len(stored) == 0 || stored[0].ID != fromID {
+			return fetchTrades(client, symbol, fromID, limit)
+		} else if len(stored) == limit {
+			return stored, nil
+		}
+
+		// download the trades after the store
+		tail, err := fetchTrades(client, symbol, lastID+1, limit-len(stored))
+		if err != nil {
+			return nil, err
+		}
+		return append(stored, tail...), nil
+	}
+
+	// the latest trades are the trades after the store and the end of the store, unless the store is too far behind
+	tail, err := fetchTrades(client, symbol, lastID+1, maxTradeHistoryLimit)
+	if err != nil {
+		return nil, err
+	} else if len(tail) == maxTradeHistoryLimit {
+		return fetchTrades(client, symbol, -1, limit)
+	} else if len(tail) >= limit {
+		return tail[len(tail)-limit:], nil
+	}
+
+	// trade IDs are sequential so the rest of the trades start a known number of trades before the last stored one
+	fromID = lastID - int64(limit-len(tail)) + 1
+	stored, err := store.loadTrades(symbol, fromID, limit-len(tail))
+	if err != nil {
+		color.Warn.Printf("ignoring the trade store: %s\n", err)
+		return fetchTrades(client, symbol, -1, limit)
+	} else if len(stored) == 0 || stored[0].ID != fromID {
+		return fetchTrades(client, symbol, -1, limit)
+	}
+	return append(stored, tail...), nil
+}
+
+func fromMillis(millis int64) time.Time {
+	return time.Unix(0, millis*int64(time.Millisecond))
+}
+
+func addStoreCommands(scope *console.Scope, client *binance.Client, symbols []binance.Symbol) {
+	var interval, from string
+	syncKlinesCommand := &console.Command{
+		Use:   "sync-klines",
+		Short: "Download missing klines for symbols into the local store",
+		Long: `Downloads the klines missing from the local store in binance.data_dir. Only klines after the last stored
+kline, or before the first one with an earlier --from, are downloaded, so the command can be run repeatedly. Without
+--from the first sync starts at the first kline of the symbol. The klines, indicators and backtest commands read from
+the store first.
+
+    sync-klines BTCUSDT ETHUSDT --interval 1h --from 2020-01-01
+		`,
+		ValidateArgs:     console.MinimumArgs(1),
+		EagerSuggestions: true,
+		Suggestions: func(env *console.Environment, args []string) []string {
+			return getSymbolList(symbols)
+		},
+		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
+			defer resetFlags(cmd)
+			if !isKlineInterval(interval) {
+				return fmt.Errorf("invalid interval: %s (expected one of %s)", interval, strings.Join(klineIntervals, ", "))
+			}
+
+			var start time.Time
+			if cmd.Flags().Changed("from") {
+				var err error
+				if start, err = parseDate(from); err != nil {
+					return err
+				}
+			}
+
+			store := getMarketStore(env.Configuration)
+			symbolMap := getSymbolMap(symbols)
+			for _, arg := range args {
+				symbol := strings.ToUpper(arg)
+				if _, ok := symbolMap[symbol]; !ok {
+					return errors.New("unknown symbol: " + arg)
+				}
+
+				added, err := store.syncKlines(client, symbol, interval, start)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("%s: %d %s klines added\n", color.LightGreen.Render(symbol), added, interval)
+			}
+			return nil
+		},
+	}
+	syncKlinesCommand.Flags().StringVar(&interval, "interval", "1d", "Candle interval")
+	syncKlinesCommand.Flags().StringVar(&from, "from", "", "Start date of the first sync (YYYY-MM-DD)")
+	syncKlinesCommand.Flags().Lookup("interval").Annotations = map[string][]string{console.Suggestions: klineIntervals}
+	scope.AddCommand(syncKlinesCommand)
+
+	var tradesFrom string
+	var limit int
+	syncTradesCommand := &console.Command{
+		Use:   "sync-trades",
+		Short: "Download new market trades for symbols into the local store",
+		Long: `Downloads the market trades after the last stored trade into the local store in binance.data_dir. Without
+--from the first sync starts at the latest trade. At most --limit trades are downloaded per symbol so busy markets
+can be caught up over several runs. The historical-market-trades and recent-market-trades commands read from the
+store first.
+
+    sync-trades BTCUSDT --from 2021-03-01
+		`,
+		ValidateArgs:     console.MinimumArgs(1),
+		EagerSuggestions: true,
+		Suggestions: func(env *console.Environment, args []string) []string {
+			return getSymbolList(symbols)
+		},
+		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
+			defer resetFlags(cmd)
+			if limit <= 0 {
+				return errors.New("limit must be greater than 0")
+			}
+
+			var start time.Time
+			if cmd.Flags().Changed("from") {
+				var err error
+				if start, err = parseDate(tradesFrom); err != nil {
+					return err
+				}
+			}
+
+			store := getMarketStore(env.Configuration)
+			symbolMap := getSymbolMap(symbols)
+			for _, arg := range args {
+				symbol := strings.ToUpper(arg)
+				if _, ok := symbolMap[symbol]; !ok {
+					return errors.New("unknown symbol: " + arg)
+				}
+
+				added, err := store.syncTrades(client, symbol, start, limit)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("%s: %d trades added\n", color.LightGreen.Render(symbol), added)
+			}
+			return nil
+		},
+	}
+	syncTradesCommand.Flags().StringVar(&tradesFrom, "from", "", "Start date of the first sync (YYYY-MM-DD)")
+	syncTradesCommand.Flags().IntVar(&limit, "limit", maxTradeSyncLimit, "Maximum number of trades to download per symbol")
+	scope.AddCommand(syncTradesCommand)
+}
@@ -0,0 +1,202 @@
+package binance
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	binance "github.com/adshao/go-binance/v2"
+	"github.com/eliquious/console"
+	"github.com/gookit/color"
+	"github.com/olekukonko/tablewriter"
+)
+
+// refreshInterval is how often live views are redrawn.
+const refreshInterval = 500 * time.Millisecond
+
+// liveView redraws its output in place on the terminal.
+type liveView struct {
+	lines int
+}
+
+// render clears the previous output and prints the new output.
+func (v *liveView) render(output string) {
+	if v.lines > 0 {
+		// move the cursor up and clear to the end of the screen
+		fmt.Printf("\033[%dA\033[J", v.lines)
+	}
+	fmt.Print(output)
+	v.lines = strings.Count(output, "\n")
+}
+
+// runLive calls the render function on every refresh until Ctrl-C is pressed or the stream is closed.
+func runLive(done <-chan struct{}, render func()) {
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-interrupt:
+			return
+		case <-done:
+			return
+		case <-ticker.C:
+			render()
+		}
+	}
+}
+
+// tickerRow is the live state of a symbol in the watch table.
+type tickerRow struct {
+	lastPrice     string
+	priceChange   string
+	changePercent string
+	bidPrice      string
+	bidQty        string
+	askPrice      string
+	askQty        string
+	volume        string
+	updated       time.Time
+}
+
+func addWatchCommand(scope *console.Scope, client *binance.Client, symbols []binance.Symbol) {
+	command := &console.Command{
+		Use:              "watch",
+		Short:            "Stream live prices for the given symbols until Ctrl-C",
+		ValidateArgs:     console.MinimumArgs(1),
+		EagerSuggestions: true,
+		Suggestions: func(env *console.Environment, args []string) []string {
+			return getSymbolList(symbols)
+		},
+		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
+			symbolMap := getSymbolMap(symbols)
+
+			var watched []string
+			for _, arg := range args {
+				symbol := strings.ToUpper(arg)
+				if _, ok := symbolMap[symbol]; !ok {
+					return errors.New("unknown symbol: " + arg)
+				}
+				watched = append(watched, symbol)
+			}
+			sort.Strings(watched)
+
+			var mu sync.Mutex
+			rows := make(map[string]*tickerRow, len(watched))
+			for _, symbol := range watched {
+				rows[symbol] = &tickerRow{}
+			}
+
+			var streamErr error
+			errHandler := func(err error) {
+				mu.Lock()
+				streamErr = err
+				mu.Unlock()
+			}
+
+			// the 24h ticker has the price change and best bid/ask
+			tickerDone, tickerStop, err := binance.WsCombinedMarketStatServe(watched, func(event *binance.WsMarketStatEvent) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				row, ok := rows[event.Symbol]
+				if !ok {
+					return
+				}
+				row.lastPrice = event.LastPrice
+				row.priceChange = event.PriceChange
+				row.changePercent = event.PriceChangePercent
+				row.bidPrice = event.BidPrice
+				row.bidQty = event.BidQty
+				row.askPrice = event.AskPrice
+				row.askQty = event.AskQty
+				row.volume = event.BaseVolume
+				row.updated = time.Now()
+			}, errHandler)
+			if err != nil {
+				return err
+			}
+			defer close(tickerStop)
+
+			// aggregate trades update the last price between tickers
+			tradeDone, tradeStop, err := binance.WsCombinedAggTradeServe(watched, func(event *binance.WsAggTradeEvent) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				if row, ok := rows[event.Symbol]; ok {
+					row.lastPrice = event.Price
+					row.updated = time.Now()
+				}
+			}, errHandler)
+			if err != nil {
+				return err
+			}
+			defer close(tradeStop)
+
+			done := make(chan struct{})
+			go func() {
+				select {
+				case <-tickerDone:
+				case <-tradeDone:
+				}
+				close(done)
+			}()
+
+			fmt.Println("Press Ctrl-C to stop")
+			var view liveView
+			runLive(done, func() {
+				mu.Lock()
+				defer mu.Unlock()
+
+				var buf bytes.Buffer
+				table := tablewriter.NewWriter(&buf)
+				table.SetHeader([]string{"Symbol", "Last", "24h Change", "24h %", "Bid", "Bid Qty", "Ask", "Ask Qty", "24h Volume"})
+				for _, symbol := range watched {
+					row := rows[symbol]
+					table.Append([]string{
+						symbol,
+						row.lastPrice,
+						formatChange(row.priceChange, row.priceChange),
+						formatChange(row.changePercent, row.changePercent+"%"),
+						color.Cyan.Render(row.bidPrice),
+						row.bidQty,
+						color.Magenta.Render(row.askPrice),
+						row.askQty,
+						row.volume,
+					})
+				}
+				table.Render()
+				fmt.Fprintf(&buf, "Updated %s\n", time.Now().Local().Format("15:04:05"))
+				view.render(buf.String())
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			return streamErr
+		},
+	}
+	scope.AddCommand(command)
+}
+
+// formatChange colors the text green or red depending on the sign of the value.
+func formatChange(value, text string) string {
+	change, err := strconv.ParseFloat(value, 64)
+	if err != nil || value == "" {
+		return text
+	}
+	if change < 0 {
+		return color.Red.Render(text)
+	}
+	return color.Green.Render(text)
+}
@@ -0,0 +1,164 @@
+package binance
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"time"
+
+	binance "github.com/adshao/go-binance/v2"
+	"github.com/eliquious/console"
+	"github.com/eliquious/mercator/costbasis"
+	"github.com/gookit/color"
+)
+
+// taxCurrency is the reporting currency of the tax report. Binance has no USD markets so USDT is used for USD.
+const taxCurrency = "USDT"
+
+// taxDateFormat is the date format used on Form 8949.
+const taxDateFormat = "01/02/2006"
+
+// isLongTerm returns true if the disposal was held for more than a year. The holding period is counted in UTC
+// calendar days, so the disposal has to be after the anniversary of the acquisition date.
+func isLongTerm(disposal costbasis.Disposal) bool {
+	return getUTCDate(disposal.Disposed).After(getUTCDate(disposal.Acquired).AddDate(1, 0, 0))
+}
+
+// getUTCDate returns the start of the UTC day of the time.
+func getUTCDate(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// getYearDisposals returns the disposals of a calendar year in UTC.
+func getYearDisposals(disposals []costbasis.Disposal, year int) []costbasis.Disposal {
+	var selected []costbasis.Disposal
+	for _, disposal := range disposals {
+		if disposal.Disposed.UTC().Year() == year {
+			selected = append(selected, disposal)
+		}
+	}
+	return selected
+}
+
+// writeTaxReport writes the disposals as Form 8949 rows. Disposals without a matching lot have an unknown
+// acquisition date and no cost, and are left for the accountant to fill in.
+func writeTaxReport(path string, disposals []costbasis.Disposal) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write([]string{"description", "date_acquired", "date_sold", "proceeds", "cost_basis", "gain_or_loss", "term"})
+	for _, disposal := range disposals {
+		acquired, term := "unknown", "unknown"
+		if !disposal.Unmatched {
+			acquired = disposal.Acquired.UTC().Format(taxDateFormat)
+			term = "short"
+			if isLongTerm(disposal) {
+				term = "long"
+			}
+		}
+		writer.Write([]string{
+			fmt.Sprintf("%s %s", formatAmount(disposal.Quantity), disposal.Asset),
+			acquired,
+			disposal.Disposed.UTC().Format(taxDateFormat),
+			fmt.Sprintf("%0.2f", disposal.Proceeds),
+			fmt.Sprintf("%0.2f", disposal.Cost),
+			fmt.Sprintf("%0.2f", disposal.Gain()),
+			term,
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func addTaxReportCommand(scope *console.Scope, client *binance.Client, symbols []binance.Symbol) {
+	var methodName, outFile string
+	var year int
+	var extra []string
+	command := &console.Command{
+		Use:   "tax-report",
+		Short: "Export the disposals of a year as a Form 8949 style CSV",
+		Long: `Replays the full trade history through a cost basis method and writes every disposal of the year to a
+CSV with the acquisition date, disposal date, proceeds, cost basis, gain and whether it was held short or long term
+(more than a year). Values are in USD using hourly kline closes of the USDT markets, since Binance has no USD markets.
+
+Binance cannot list the symbols an account has traded, so the markets of every asset with a balance are loaded. Add
+positions closed since with --symbols. Deposits are not tracked: anything sold without a matching buy has an unknown
+acquisition date and no cost basis.
+
+    tax-report --year 2025 --method fifo --out taxes-2025.csv
+		`,
+		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
+			defer resetFlags(cmd)
+			method, err := costbasis.ParseMethod(methodName)
+			if err != nil {
+				return err
+			}
+			path := outFile
+			if path == "" {
+				path = fmt.Sprintf("tax-report-%d-%s.csv", year, method)
+			}
+
+			account, err := client.NewGetAccountService().Do(context.Background())
+			if err != nil {
+				return err
+			}
+			traded, err := getTradedSymbols(account, symbols, extra)
+			if err != nil {
+				return err
+			}
+
+			ledger, err := buildTradeLedger(client, getMarketStore(env.Configuration), symbols, traded, method, taxCurrency)
+			if err != nil {
+				return err
+			}
+
+			disposals := getYearDisposals(ledger.Disposals, year)
+			if err := writeTaxReport(path, disposals); err != nil {
+				return err
+			}
+
+			var proceeds, cost, shortTerm, longTerm float64
+			var unmatched int
+			for _, disposal := range disposals {
+				proceeds += disposal.Proceeds
+				cost += disposal.Cost
+				switch {
+				case disposal.Unmatched:
+					unmatched++
+				case isLongTerm(disposal):
+					longTerm += disposal.Gain()
+				default:
+					shortTerm += disposal.Gain()
+				}
+			}
+
+			fmt.Printf("\n%s:       %d\n", color.LightGreen.Render("Year"), year)
+			fmt.Printf("%s:     %s\n", color.LightGreen.Render("Method"), method)
+			fmt.Printf("%s:  %d\n", color.LightGreen.Render("Disposals"), len(disposals))
+			fmt.Printf("%s:   %0.2f USD\n", color.LightGreen.Render("Proceeds"), proceeds)
+			fmt.Printf("%s: %0.2f USD\n", color.LightGreen.Render("Cost Basis"), cost)
+			fmt.Printf("%s: %s USD\n", color.LightGreen.Render("Short Term"), formatGainValue(shortTerm))
+			fmt.Printf("%s:  %s USD\n\n", color.LightGreen.Render("Long Term"), formatGainValue(longTerm))
+			if unmatched > 0 {
+				color.Warn.Printf("%d disposals have no matching buy and need an acquisition date and cost basis\n", unmatched)
+			}
+			fmt.Printf("Tax report written to %s\n", path)
+			return nil
+		},
+	}
+	command.Flags().IntVar(&year, "year", time.Now().Year()-1, "Tax year")
+	command.Flags().StringVar(&methodName, "method", string(costbasis.FIFO), "Cost basis method: fifo, lifo or average")
+	command.Flags().StringVar(&outFile, "out", "", "CSV file to write (default tax-report-YEAR-METHOD.csv)")
+	command.Flags().StringSliceVar(&extra, "symbols", []string{}, "Additional symbols to load trades for")
+	command.Flags().Lookup("method").Annotations = map[string][]string{console.Suggestions: {"fifo", "lifo", "average"}}
+	scope.AddCommand(command)
+}
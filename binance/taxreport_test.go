@@ -0,0 +1,31 @@
+package binance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eliquious/mercator/costbasis"
+)
+
+func TestIsLongTerm(t *testing.T) {
+	acquired := time.Date(2023, 3, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		disposed time.Time
+		expected bool
+	}{
+		{"same day", acquired.Add(time.Hour), false},
+		// a year and a few hours is still the anniversary date
+		{"anniversary", time.Date(2024, 3, 1, 15, 0, 0, 0, time.UTC), false},
+		{"day after anniversary", time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), true},
+		{"years later", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), true},
+		// the dates are taken in UTC
+		{"other timezone", time.Date(2024, 3, 2, 0, 30, 0, 0, time.FixedZone("CET", 3600)), false},
+	}
+	for _, test := range tests {
+		disposal := costbasis.Disposal{Acquired: acquired, Disposed: test.disposed}
+		if actual := isLongTerm(disposal); actual != test.expected {
+			t.Errorf("%s: expected %t, got %t", test.name, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !windows
+// +build !windows
+
+package binance
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// getTerminalSize returns the number of columns and rows of the terminal. The default size is used if stdout is not
+// a terminal.
+func getTerminalSize() (int, int) {
+	ws, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil || ws.Col == 0 || ws.Row == 0 {
+		return defaultTerminalWidth, defaultTerminalHeight
+	}
+	return int(ws.Col), int(ws.Row)
+}
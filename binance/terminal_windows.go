@@ -0,0 +1,17 @@
+package binance
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// getTerminalSize returns the number of columns and rows of the console window. The default size is used if stdout
+// is not a console.
+func getTerminalSize() (int, int) {
+	var info windows.ConsoleScreenBufferInfo
+	if err := windows.GetConsoleScreenBufferInfo(windows.Handle(os.Stdout.Fd()), &info); err != nil {
+		return defaultTerminalWidth, defaultTerminalHeight
+	}
+	return int(info.Window.Right-info.Window.Left) + 1, int(info.Window.Bottom-info.Window.Top) + 1
+}
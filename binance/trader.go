@@ -0,0 +1,155 @@
+package binance
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+
+	binance "github.com/adshao/go-binance/v2"
+	"github.com/eliquious/console"
+	"github.com/spf13/viper"
+)
+
+// trader places and manages orders. It is implemented by the exchange and by the paper trading engine so the order
+// commands work the same way in both modes.
+type trader interface {
+	createLimitOrder(info binance.Symbol, side binance.SideType, quantity, price string) (*binance.CreateOrderResponse, error)
+	createOCO(info binance.Symbol, quantity, price, stopPrice, stopLimitPrice string) (*binance.CreateOCOResponse, error)
+	getOrder(symbol string, orderID int64) (*binance.Order, error)
+	listOpenOrders(symbol string) ([]*binance.Order, error)
+	cancelOrder(symbol string, orderID int64) (*binance.CancelOrderResponse, error)
+	cancelOpenOrders(symbol string) (*binance.CancelOpenOrdersResponse, error)
+	getAccount() (*binance.Account, error)
+	listTrades(symbol string, limit int) ([]*binance.TradeV3, error)
+}
+
+// traders selects the exchange or the paper trading engine depending on the configuration.
+type traders struct {
+	exchange *exchangeTrader
+	symbols  []binance.Symbol
+
+	mu    sync.Mutex
+	paper *paperTrader
+}
+
+func newTraders(client *binance.Client, symbols []binance.Symbol) *traders {
+	return &traders{exchange: &exchangeTrader{client: client, stream: newUserStream(client)}, symbols: symbols}
+}
+
+// get returns the paper trading engine if `binance.paper` is set or the command was run with --dry-run. Otherwise the
+// exchange is returned. The command is nil outside of commands, such as in scripts.
+func (t *traders) get(env *console.Environment, cmd *console.Command) (trader, error) {
+	dryRun := env.Configuration.GetBool("binance.paper")
+	if cmd != nil {
+		if flag := cmd.Flags().Lookup("dry-run"); flag != nil && flag.Changed {
+			dryRun, _ = cmd.Flags().GetBool("dry-run")
+		}
+	}
+	if !dryRun {
+		return t.exchange, nil
+	}
+	return t.getPaper(env)
+}
+
+// getPaper loads the paper trading engine from disk the first time it is used.
+func (t *traders) getPaper(env *console.Environment) (*paperTrader, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	path := env.Configuration.GetString("binance.paper_file")
+	if t.paper == nil || t.paper.path != path {
+		paper, err := loadPaperTrader(t.exchange.client, t.symbols, path)
+		if err != nil {
+			return nil, err
+		}
+		t.paper = paper
+	}
+	t.paper.setFee(env.Configuration.GetFloat64("binance.paper_fee"))
+	return t.paper, nil
+}
+
+// isPaper checks if the trader is the paper trading engine.
+func isPaper(t trader) bool {
+	_, ok := t.(*paperTrader)
+	return ok
+}
+
+// getConfigDir returns the directory of the console configuration file.
+func getConfigDir(conf *viper.Viper) string {
+	if file := conf.ConfigFileUsed(); file != "" {
+		return filepath.Dir(file)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".mercator"
+	}
+	return filepath.Join(home, ".mercator")
+}
+
+// exchangeTrader sends orders to the Binance exchange. The account is read from the user data stream when it is
+// running.
+type exchangeTrader struct {
+	client *binance.Client
+	stream *userStream
+}
+
+func (t *exchangeTrader) createLimitOrder(info binance.Symbol, side binance.SideType, quantity, price string) (*binance.CreateOrderResponse, error) {
+	return t.client.NewCreateOrderService().
+		Symbol(info.Symbol).
+		Side(side).
+		Type(binance.OrderTypeLimit).
+		TimeInForce(binance.TimeInForceTypeGTC).
+		Quantity(quantity).
+		Price(price).
+		Do(context.Background())
+}
+
+func (t *exchangeTrader) createOCO(info binance.Symbol, quantity, price, stopPrice, stopLimitPrice string) (*binance.CreateOCOResponse, error) {
+	return t.client.NewCreateOCOService().
+		Symbol(info.Symbol).
+		Side(binance.SideTypeSell).
+		Quantity(quantity).
+		Price(price).
+		StopPrice(stopPrice).
+		StopLimitPrice(stopLimitPrice).
+		StopLimitTimeInForce(binance.TimeInForceTypeGTC).
+		Do(context.Background())
+}
+
+func (t *exchangeTrader) getOrder(symbol string, orderID int64) (*binance.Order, error) {
+	return t.client.NewGetOrderService().Symbol(symbol).OrderID(orderID).Do(context.Background())
+}
+
+func (t *exchangeTrader) listOpenOrders(symbol string) ([]*binance.Order, error) {
+	exchange := t.client.NewListOpenOrdersService()
+	if symbol != "" {
+		exchange = exchange.Symbol(symbol)
+	}
+	return exchange.Do(context.Background())
+}
+
+func (t *exchangeTrader) cancelOrder(symbol string, orderID int64) (*binance.CancelOrderResponse, error) {
+	return t.client.NewCancelOrderService().Symbol(symbol).OrderID(orderID).Do(context.Background())
+}
+
+func (t *exchangeTrader) cancelOpenOrders(symbol string) (*binance.CancelOpenOrdersResponse, error) {
+	return t.client.NewCancelOpenOrdersService().Symbol(symbol).Do(context.Background())
+}
+
+func (t *exchangeTrader) getAccount() (*binance.Account, error) {
+	if t.stream != nil {
+		if account, ok := t.stream.getAccount(); ok {
+			return account, nil
+		}
+	}
+	return t.client.NewGetAccountService().Do(context.Background())
+}
+
+func (t *exchangeTrader) listTrades(symbol string, limit int) ([]*binance.TradeV3, error) {
+	exchange := t.client.NewListTradesService().Symbol(symbol)
+	if limit > 0 {
+		exchange = exchange.Limit(limit)
+	}
+	return exchange.Do(context.Background())
+}
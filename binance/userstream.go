@@ -0,0 +1,338 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	binance "github.com/adshao/go-binance/v2"
+	"github.com/eliquious/console"
+	"github.com/gookit/color"
+)
+
+// keepaliveInterval is how often the listen key is renewed. Binance expires listen keys after 60 minutes.
+const keepaliveInterval = 30 * time.Minute
+
+// userEvent is the common header of the user data stream events.
+type userEvent struct {
+	Event string `json:"e"`
+	Time  int64  `json:"E"`
+}
+
+// accountPositionEvent is sent when the account balances change.
+type accountPositionEvent struct {
+	Event      string `json:"e"`
+	Time       int64  `json:"E"`
+	UpdateTime int64  `json:"u"`
+	Balances   []struct {
+		Asset  string `json:"a"`
+		Free   string `json:"f"`
+		Locked string `json:"l"`
+	} `json:"B"`
+}
+
+// executionReportEvent is sent when an order is created, changed or filled. Every field is listed because
+// encoding/json falls back to a case-insensitive match for keys without a field.
+type executionReportEvent struct {
+	Event                   string `json:"e"`
+	Time                    int64  `json:"E"`
+	Symbol                  string `json:"s"`
+	ClientOrderID           string `json:"c"`
+	Side                    string `json:"S"`
+	Type                    string `json:"o"`
+	TimeInForce             string `json:"f"`
+	Quantity                string `json:"q"`
+	Price                   string `json:"p"`
+	StopPrice               string `json:"P"`
+	IcebergQuantity         string `json:"F"`
+	OrderListID             int64  `json:"g"`
+	OrigClientOrderID       string `json:"C"`
+	ExecutionType           string `json:"x"`
+	Status                  string `json:"X"`
+	RejectReason            string `json:"r"`
+	OrderID                 int64  `json:"i"`
+	LastQuantity            string `json:"l"`
+	CumulativeQuantity      string `json:"z"`
+	LastPrice               string `json:"L"`
+	Commission              string `json:"n"`
+	CommissionAsset         string `json:"N"`
+	TransactionTime         int64  `json:"T"`
+	TradeID                 int64  `json:"t"`
+	Ignore                  int64  `json:"I"`
+	IsWorking               bool   `json:"w"`
+	IsMaker                 bool   `json:"m"`
+	IgnoreMaker             bool   `json:"M"`
+	CreateTime              int64  `json:"O"`
+	CumulativeQuoteQuantity string `json:"Z"`
+	LastQuoteQuantity       string `json:"Y"`
+	QuoteOrderQuantity      string `json:"Q"`
+	WorkingTime             int64  `json:"W"`
+	SelfTradePrevention     string `json:"V"`
+}
+
+// userStream listens to the user data stream in the background. It prints fills, order changes and balance deltas
+// as they happen and keeps an account snapshot so balances can be read without a REST call.
+type userStream struct {
+	client *binance.Client
+
+	mu        sync.Mutex
+	listenKey string
+	stop      chan struct{}
+	started   time.Time
+	updated   time.Time
+	account   *binance.Account
+}
+
+func newUserStream(client *binance.Client) *userStream {
+	return &userStream{client: client}
+}
+
+// running checks if the stream is connected.
+func (s *userStream) running() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stop != nil
+}
+
+// start loads the account snapshot, connects to the stream and renews the listen key until stopped.
+func (s *userStream) start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		return errors.New("user data stream is already running")
+	}
+
+	listenKey, err := s.client.NewStartUserStreamService().Do(context.Background())
+	if err != nil {
+		return err
+	}
+
+	done, stopStream, err := binance.WsUserDataServe(listenKey, s.handle, func(err error) {
+		color.Warn.Printf("\nuser data stream: %s\n", err)
+	})
+	if err != nil {
+		return err
+	}
+
+	// the snapshot is loaded after connecting so no updates are missed. Events wait on the lock until it is set.
+	account, err := s.client.NewGetAccountService().Do(context.Background())
+	if err != nil {
+		close(stopStream)
+		return err
+	}
+
+	stop := make(chan struct{})
+	s.listenKey = listenKey
+	s.stop = stop
+	s.started = time.Now()
+	s.updated = s.started
+	s.account = account
+
+	go func() {
+		ticker := time.NewTicker(keepaliveInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				close(stopStream)
+				s.client.NewCloseUserStreamService().ListenKey(listenKey).Do(context.Background())
+				return
+			case <-done:
+				color.Warn.Println("\nuser data stream disconnected")
+				s.reset(stop)
+				return
+			case <-ticker.C:
+				if err := s.client.NewKeepaliveUserStreamService().ListenKey(listenKey).Do(context.Background()); err != nil {
+					color.Warn.Printf("\nuser data stream keepalive failed: %s\n", err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// close stops the stream and discards the account snapshot.
+func (s *userStream) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop == nil {
+		return errors.New("user data stream is not running")
+	}
+
+	close(s.stop)
+	s.stop = nil
+	s.account = nil
+	return nil
+}
+
+// reset clears the state if the stream has not been restarted since it disconnected.
+func (s *userStream) reset(stop chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop == stop {
+		s.stop = nil
+		s.account = nil
+	}
+}
+
+// getAccount returns a copy of the account snapshot if the stream is running.
+func (s *userStream) getAccount() (*binance.Account, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.account == nil {
+		return nil, false
+	}
+
+	account := *s.account
+	account.Balances = append([]binance.Balance(nil), s.account.Balances...)
+	return &account, true
+}
+
+func (s *userStream) handle(message []byte) {
+	var header userEvent
+	if err := json.Unmarshal(message, &header); err != nil {
+		color.Warn.Printf("\nuser data stream: %s\n", err)
+		return
+	}
+
+	switch header.Event {
+	case "outboundAccountPosition":
+		var event accountPositionEvent
+		if err := json.Unmarshal(message, &event); err != nil {
+			color.Warn.Printf("\nuser data stream: %s\n", err)
+			return
+		}
+		s.updateBalances(&event)
+	case "executionReport":
+		var event executionReportEvent
+		if err := json.Unmarshal(message, &event); err != nil {
+			color.Warn.Printf("\nuser data stream: %s\n", err)
+			return
+		}
+		printExecutionReport(&event)
+	}
+}
+
+// updateBalances applies the balance changes to the account snapshot and prints the deltas.
+func (s *userStream) updateBalances(event *accountPositionEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.account == nil {
+		return
+	}
+
+	for _, update := range event.Balances {
+		var previous float64
+		found := false
+		for index := range s.account.Balances {
+			balance := &s.account.Balances[index]
+			if balance.Asset == update.Asset {
+				previous = parseBalanceTotal(balance.Free, balance.Locked)
+				balance.Free = update.Free
+				balance.Locked = update.Locked
+				found = true
+				break
+			}
+		}
+		if !found {
+			s.account.Balances = append(s.account.Balances, binance.Balance{Asset: update.Asset, Free: update.Free, Locked: update.Locked})
+		}
+		s.updated = time.Unix(0, event.UpdateTime*int64(time.Millisecond))
+
+		delta := parseBalanceTotal(update.Free, update.Locked) - previous
+		if delta != 0 {
+			fmt.Printf("\n%s %s %s (free %s, locked %s)\n",
+				color.LightWhite.Render("[balance]"),
+				color.LightBlue.Render(update.Asset),
+				formatChange(formatAmount(delta), fmt.Sprintf("%+0.8f", delta)),
+				update.Free, update.Locked)
+		}
+	}
+}
+
+func parseBalanceTotal(free, locked string) float64 {
+	f, _ := strconv.ParseFloat(free, 64)
+	l, _ := strconv.ParseFloat(locked, 64)
+	return f + l
+}
+
+// printExecutionReport prints fills and order state changes.
+func printExecutionReport(event *executionReportEvent) {
+	side := formatSide(binance.SideType(event.Side))
+	if event.ExecutionType == "TRADE" {
+		fmt.Printf("\n%s %s %s %s @ %s (%s/%s filled, fee %s %s) order %d\n",
+			color.LightWhite.Render("[fill]"),
+			side,
+			event.LastQuantity,
+			color.LightBlue.Render(event.Symbol),
+			event.LastPrice,
+			event.CumulativeQuantity,
+			event.Quantity,
+			event.Commission,
+			event.CommissionAsset,
+			event.OrderID)
+		return
+	}
+
+	fmt.Printf("\n%s %s %s %s %s @ %s order %d %s\n",
+		color.LightWhite.Render("[order]"),
+		color.LightBlue.Render(event.Symbol),
+		side,
+		event.Type,
+		event.Quantity,
+		event.Price,
+		event.OrderID,
+		color.LightYellow.Render(event.Status))
+	if event.RejectReason != "" && event.RejectReason != "NONE" {
+		fmt.Printf("  %s: %s\n", color.LightGreen.Render("Reject Reason"), event.RejectReason)
+	}
+}
+
+func addUserStreamCommand(scope *console.Scope, traders *traders) {
+	stream := traders.exchange.stream
+	command := &console.Command{
+		Use:   "user-stream",
+		Short: "Start, stop or check the user data stream (start|stop|status)",
+		Long: `The user data stream runs in the background and prints fills, order changes and balance deltas as they
+happen. While it is running account-balance answers from the stream instead of the REST API. Setting
+binance.user_stream in the configuration starts it when the scope is used.`,
+		ValidateArgs:     console.ExactArgs(1),
+		EagerSuggestions: true,
+		Suggestions: func(env *console.Environment, args []string) []string {
+			return []string{"start", "stop", "status"}
+		},
+		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
+			switch args[0] {
+			case "start":
+				if err := stream.start(); err != nil {
+					return err
+				}
+				fmt.Println("User data stream started")
+			case "stop":
+				if err := stream.close(); err != nil {
+					return err
+				}
+				fmt.Println("User data stream stopped")
+			case "status":
+				stream.mu.Lock()
+				defer stream.mu.Unlock()
+				if stream.stop == nil {
+					fmt.Printf("%s: %s\n", color.LightGreen.Render("Status"), "stopped")
+					return nil
+				}
+				fmt.Printf("%s: %s\n", color.LightGreen.Render("Status"), "running")
+				fmt.Printf("%s: %s\n", color.LightGreen.Render("Started"), stream.started.Local().Format(time.RFC1123))
+				fmt.Printf("%s: %s\n", color.LightGreen.Render("Updated"), stream.updated.Local().Format(time.RFC1123))
+			default:
+				return errors.New("expected start, stop or status")
+			}
+			return nil
+		},
+	}
+	scope.AddCommand(command)
+}
@@ -0,0 +1,192 @@
+package binance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	binance "github.com/adshao/go-binance/v2"
+	"github.com/eliquious/console"
+	"github.com/eliquious/mercator/output"
+	"github.com/gookit/color"
+	"github.com/spf13/viper"
+)
+
+// addressBookKey is the configuration key for the withdrawal address book.
+const addressBookKey = "binance.addresses"
+
+// withdrawAddress is a named withdrawal destination in the address book.
+type withdrawAddress struct {
+	Asset   string `mapstructure:"asset"`
+	Address string `mapstructure:"address"`
+	Tag     string `mapstructure:"tag"`
+	Network string `mapstructure:"network"`
+}
+
+// getAddressBook reads the withdrawal address book from the configuration.
+func getAddressBook(conf *viper.Viper) (map[string]withdrawAddress, error) {
+	book := map[string]withdrawAddress{}
+	if err := conf.UnmarshalKey(addressBookKey, &book); err != nil {
+		return nil, fmt.Errorf("invalid address book: %s", err)
+	}
+	return book, nil
+}
+
+func getAddressBookNames(conf *viper.Viper) []string {
+	book, err := getAddressBook(conf)
+	if err != nil {
+		return []string{}
+	}
+
+	names := make([]string, 0, len(book))
+	for name := range book {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func addWithdrawCommands(scope *console.Scope, client *binance.Client, symbols []binance.Symbol) {
+	addressesCommand := &console.Command{
+		Use:   "withdraw-addresses",
+		Short: "List the withdrawal address book",
+		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
+			book, err := getAddressBook(env.Configuration)
+			if err != nil {
+				return err
+			}
+			return output.Render(env, cmd, newAddressBookResult(book))
+		},
+	}
+	output.AddFlag(addressesCommand)
+	scope.AddCommand(addressesCommand)
+
+	var destination string
+	var amount float64
+	withdrawCommand := &console.Command{
+		Use:   "withdraw",
+		Short: "Withdraw an asset to an address in the address book",
+		Long: `Withdrawals can only be sent to a named destination in the address book. Addresses are never entered at
+the prompt. The address book is kept in the console configuration file:
+
+    binance:
+      addresses:
+        ledger-btc:
+          asset: BTC
+          network: BTC
+          address: bc1q...
+
+The asset name has to be typed to confirm every withdrawal, even with --yes, so withdrawals cannot run from scripts.
+
+    withdraw --to ledger-btc --amount 0.5
+		`,
+		RequiredFlags: []string{"to", "amount"},
+		Suggestions: func(env *console.Environment, args []string) []string {
+			if contains(args, "--to") && len(args) > 2 {
+				return getAddressBookNames(env.Configuration)
+			}
+			return []string{}
+		},
+		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
+			if !cmd.Flags().Changed("to") {
+				return errors.New("destination is required")
+			}
+			if !cmd.Flags().Changed("amount") {
+				return errors.New("amount is required")
+			} else if amount <= 0 {
+				return errors.New("amount must be positive")
+			}
+
+			book, err := getAddressBook(env.Configuration)
+			if err != nil {
+				return err
+			}
+
+			entry, ok := book[strings.ToLower(destination)]
+			if !ok {
+				return fmt.Errorf("unknown destination: %s", destination)
+			} else if entry.Asset == "" || entry.Address == "" {
+				return fmt.Errorf("destination %s requires an asset and address", destination)
+			}
+			asset := strings.ToUpper(entry.Asset)
+
+			// check the asset can be withdrawn
+			details, err := getAssetDetail(client, asset)
+			if err != nil {
+				return err
+			}
+			if !details.WithdrawStatus {
+				return fmt.Errorf("withdrawals are currently disabled for %s", asset)
+			}
+			if amount < details.MinWithdrawAmount {
+				return fmt.Errorf("amount is below the minimum withdrawal of %s %s", formatAmount(details.MinWithdrawAmount), asset)
+			}
+			if amount <= details.WithdrawFee {
+				return fmt.Errorf("amount does not cover the withdrawal fee of %s %s", formatAmount(details.WithdrawFee), asset)
+			}
+
+			// check the account has enough free balance
+			free, _, err := getAssetBalance(client, asset)
+			if err != nil {
+				return err
+			}
+			if amount > free {
+				return fmt.Errorf("insufficient balance: %s %s free", formatAmount(free), asset)
+			}
+
+			fmt.Printf("\n%s:  %s\n", color.LightGreen.Render("Destination"), destination)
+			fmt.Printf("%s:      %s\n", color.LightGreen.Render("Address"), entry.Address)
+			if entry.Tag != "" {
+				fmt.Printf("%s:          %s\n", color.LightGreen.Render("Tag"), entry.Tag)
+			}
+			if entry.Network != "" {
+				fmt.Printf("%s:      %s\n", color.LightGreen.Render("Network"), entry.Network)
+			}
+			fmt.Printf("%s:       %s %s\n", color.LightGreen.Render("Amount"), formatAmount(amount), color.LightBlue.Render(asset))
+			fmt.Printf("%s:          %s %s\n", color.LightGreen.Render("Fee"), formatAmount(details.WithdrawFee), color.LightBlue.Render(asset))
+			fmt.Printf("%s:   %s %s\n\n", color.LightGreen.Render("Net Amount"), formatAmount(amount-details.WithdrawFee), color.LightBlue.Render(asset))
+
+			// withdrawals always ask, even with --yes, and fail without a terminal
+			answer, err := prompt(env, fmt.Sprintf("Type %s to confirm the withdrawal: ", asset))
+			if err == nil && answer != asset {
+				err = errNotConfirmed
+			}
+			if err != nil {
+				return fmt.Errorf("withdrawal cancelled: %s", err)
+			}
+
+			exchange := client.NewCreateWithdrawService().
+				Asset(asset).
+				Address(entry.Address).
+				Amount(formatAmount(amount)).
+				Name(destination)
+			if entry.Tag != "" {
+				exchange = exchange.AddressTag(entry.Tag)
+			}
+			if entry.Network != "" {
+				exchange = exchange.Network(entry.Network)
+			}
+
+			resp, err := exchange.Do(context.Background())
+			if err != nil {
+				return err
+			}
+			if !resp.Success {
+				return fmt.Errorf("withdrawal failed: %s", resp.Msg)
+			}
+
+			fmt.Printf("%s: %s\n", color.LightGreen.Render("Withdrawal ID"), resp.ID)
+			return nil
+		},
+	}
+	withdrawCommand.Flags().StringVar(&destination, "to", "", "Name of the destination in the address book")
+	withdrawCommand.Flags().Float64Var(&amount, "amount", 0, "Amount to withdraw including fees")
+	scope.AddCommand(withdrawCommand)
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', -1, 64)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/viper"
+)
+
+// loadConfig reads the console configuration file if it exists. The file defaults to ~/.mercator/config.yaml and
+// can be changed with the MERCATOR_CONFIG env variable.
+func loadConfig(conf *viper.Viper) error {
+	configFile := os.Getenv("MERCATOR_CONFIG")
+	if configFile == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+		configFile = filepath.Join(home, ".mercator", "config.yaml")
+	}
+
+	conf.SetConfigFile(configFile)
+	if err := conf.ReadInConfig(); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+// Package costbasis matches disposals of assets against the lots they were acquired in. Costs and proceeds are in a
+// single reporting currency so gains can be added up across assets.
+package costbasis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Method selects the lots a disposal is matched against.
+type Method string
+
+// Supported cost basis methods.
+const (
+	FIFO    Method = "fifo"
+	LIFO    Method = "lifo"
+	Average Method = "average"
+)
+
+// Methods lists the supported cost basis methods.
+var Methods = []Method{FIFO, LIFO, Average}
+
+// ParseMethod parses a cost basis method name.
+func ParseMethod(name string) (Method, error) {
+	for _, method := range Methods {
+		if strings.EqualFold(name, string(method)) {
+			return method, nil
+		}
+	}
+	return "", fmt.Errorf("unknown cost basis method: %s (expected fifo, lifo or average)", name)
+}
+
+// Lot is a quantity of an asset acquired at a cost.
+type Lot struct {
+	Asset    string
+	Quantity float64
+	Cost     float64
+	Acquired time.Time
+}
+
+// Disposal is a quantity of an asset disposed of from a single lot. Quantities disposed of without a matching lot,
+// such as assets deposited from elsewhere, have no cost and are marked as unmatched.
+type Disposal struct {
+	Asset     string
+	Quantity  float64
+	Proceeds  float64
+	Cost      float64
+	Acquired  time.Time
+	Disposed  time.Time
+	Unmatched bool
+}
+
+// Gain is the proceeds less the cost.
+func (d Disposal) Gain() float64 {
+	return d.Proceeds - d.Cost
+}
+
+// Ledger tracks the open lots of every asset and the disposals matched against them.
+type Ledger struct {
+	method    Method
+	lots      map[string][]*Lot
+	Disposals []Disposal
+}
+
+// NewLedger creates an empty ledger using the cost basis method.
+func NewLedger(method Method) *Ledger {
+	return &Ledger{method: method, lots: make(map[string][]*Lot)}
+}
+
+// Acquire adds a lot. With the average method the cost is pooled across the open lots so every unit has the same
+// cost, while each lot keeps its own acquisition time for the holding period.
+func (l *Ledger) Acquire(asset string, quantity, cost float64, at time.Time) {
+	if quantity <= 0 {
+		return
+	}
+
+	lots := append(l.lots[asset], &Lot{Asset: asset, Quantity: quantity, Cost: cost, Acquired: at})
+	l.lots[asset] = lots
+	if l.method == Average {
+		total, totalCost := l.Holding(asset)
+		for _, lot := range lots {
+			lot.Cost = totalCost * lot.Quantity / total
+		}
+	}
+}
+
+// Dispose matches the quantity against the open lots and records the disposals. The proceeds are split between the
+// lots by quantity.
+func (l *Ledger) Dispose(asset string, quantity, proceeds float64, at time.Time) []Disposal {
+	if quantity <= 0 {
+		return nil
+	}
+
+	var disposals []Disposal
+	remaining := quantity
+	for remaining > 0 && len(l.lots[asset]) > 0 {
+		lots := l.lots[asset]
+
+		// FIFO and average take the oldest lot and LIFO the newest
+		index := 0
+		if l.method == LIFO {
+			index = len(lots) - 1
+		}
+		lot := lots[index]
+
+		matched := remaining
+		if lot.Quantity < matched {
+			matched = lot.Quantity
+		}
+		cost := lot.Cost * matched / lot.Quantity
+		disposals = append(disposals, Disposal{
+			Asset:    asset,
+			Quantity: matched,
+			Proceeds: proceeds * matched / quantity,
+			Cost:     cost,
+			Acquired: lot.Acquired,
+			Disposed: at,
+		})
+
+		lot.Quantity -= matched
+		lot.Cost -= cost
+		remaining -= matched
+
+		// tiny remainders are left by float rounding
+		if lot.Quantity <= 1e-12 {
+			l.lots[asset] = append(lots[:index], lots[index+1:]...)
+		}
+	}
+
+	if remaining > 1e-12 {
+		disposals = append(disposals, Disposal{
+			Asset:     asset,
+			Quantity:  remaining,
+			Proceeds:  proceeds * remaining / quantity,
+			Disposed:  at,
+			Unmatched: true,
+		})
+	}
+
+	l.Disposals = append(l.Disposals, disposals...)
+	return disposals
+}
+
+// Holding returns the open quantity and remaining cost of an asset.
+func (l *Ledger) Holding(asset string) (float64, float64) {
+	var quantity, cost float64
+	for _, lot := range l.lots[asset] {
+		quantity += lot.Quantity
+		cost += lot.Cost
+	}
+	return quantity, cost
+}
+
+// Lots returns the open lots of an asset.
+func (l *Ledger) Lots(asset string) []Lot {
+	lots := make([]Lot, 0, len(l.lots[asset]))
+	for _, lot := range l.lots[asset] {
+		lots = append(lots, *lot)
+	}
+	return lots
+}
+
+// Assets returns the sorted assets with open lots or disposals.
+func (l *Ledger) Assets() []string {
+	seen := make(map[string]bool)
+	for asset, lots := range l.lots {
+		if len(lots) > 0 {
+			seen[asset] = true
+		}
+	}
+	for _, disposal := range l.Disposals {
+		seen[disposal.Asset] = true
+	}
+
+	assets := make([]string, 0, len(seen))
+	for asset := range seen {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+	return assets
+}
+
+// Realized returns the realized gain of an asset.
+func (l *Ledger) Realized(asset string) float64 {
+	var gain float64
+	for _, disposal := range l.Disposals {
+		if disposal.Asset == asset {
+			gain += disposal.Gain()
+		}
+	}
+	return gain
+}
@@ -0,0 +1,174 @@
+package costbasis
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+var (
+	day1 = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	day2 = day1.AddDate(0, 0, 1)
+	day3 = day1.AddDate(0, 0, 2)
+)
+
+type acquisition struct {
+	quantity float64
+	cost     float64
+	at       time.Time
+}
+
+func newTestLedger(method Method, lots []acquisition) *Ledger {
+	ledger := NewLedger(method)
+	for _, lot := range lots {
+		ledger.Acquire("BTC", lot.quantity, lot.cost, lot.at)
+	}
+	return ledger
+}
+
+func assertFloat(t *testing.T, name string, expected, actual float64) {
+	t.Helper()
+	if math.Abs(expected-actual) > 1e-9 {
+		t.Errorf("%s: expected %f, got %f", name, expected, actual)
+	}
+}
+
+func assertDisposals(t *testing.T, name string, expected, actual []Disposal) {
+	t.Helper()
+	if len(expected) != len(actual) {
+		t.Fatalf("%s: expected %d disposals, got %d", name, len(expected), len(actual))
+	}
+	for index := range expected {
+		if expected[index].Asset != actual[index].Asset || expected[index].Unmatched != actual[index].Unmatched {
+			t.Errorf("%s[%d]: expected %+v, got %+v", name, index, expected[index], actual[index])
+		}
+		if !expected[index].Acquired.Equal(actual[index].Acquired) || !expected[index].Disposed.Equal(actual[index].Disposed) {
+			t.Errorf("%s[%d]: expected %+v, got %+v", name, index, expected[index], actual[index])
+		}
+		assertFloat(t, name+" quantity", expected[index].Quantity, actual[index].Quantity)
+		assertFloat(t, name+" proceeds", expected[index].Proceeds, actual[index].Proceeds)
+		assertFloat(t, name+" cost", expected[index].Cost, actual[index].Cost)
+	}
+}
+
+func TestDispose(t *testing.T) {
+	lots := []acquisition{{2, 200, day1}, {2, 300, day2}}
+	tests := []struct {
+		name               string
+		method             Method
+		lots               []acquisition
+		quantity, proceeds float64
+		expected           []Disposal
+		holding, cost      float64
+	}{
+		// the second lot is only partly used and keeps the rest of its cost
+		{
+			"fifo", FIFO, lots, 3, 600,
+			[]Disposal{
+				{Asset: "BTC", Quantity: 2, Proceeds: 400, Cost: 200, Acquired: day1, Disposed: day3},
+				{Asset: "BTC", Quantity: 1, Proceeds: 200, Cost: 150, Acquired: day2, Disposed: day3},
+			},
+			1, 150,
+		},
+		{
+			"lifo", LIFO, lots, 3, 600,
+			[]Disposal{
+				{Asset: "BTC", Quantity: 2, Proceeds: 400, Cost: 300, Acquired: day2, Disposed: day3},
+				{Asset: "BTC", Quantity: 1, Proceeds: 200, Cost: 100, Acquired: day1, Disposed: day3},
+			},
+			1, 100,
+		},
+		{
+			"within one lot", FIFO, lots, 0.5, 100,
+			[]Disposal{
+				{Asset: "BTC", Quantity: 0.5, Proceeds: 100, Cost: 50, Acquired: day1, Disposed: day3},
+			},
+			3.5, 450,
+		},
+		// the quantity without a lot has no cost
+		{
+			"unmatched", FIFO, []acquisition{{1, 100, day1}}, 3, 600,
+			[]Disposal{
+				{Asset: "BTC", Quantity: 1, Proceeds: 200, Cost: 100, Acquired: day1, Disposed: day3},
+				{Asset: "BTC", Quantity: 2, Proceeds: 400, Disposed: day3, Unmatched: true},
+			},
+			0, 0,
+		},
+		{
+			"no lots", LIFO, nil, 1, 50,
+			[]Disposal{
+				{Asset: "BTC", Quantity: 1, Proceeds: 50, Disposed: day3, Unmatched: true},
+			},
+			0, 0,
+		},
+		{
+			"nothing disposed", FIFO, lots, 0, 0,
+			nil,
+			4, 500,
+		},
+	}
+	for _, test := range tests {
+		ledger := newTestLedger(test.method, test.lots)
+		assertDisposals(t, test.name, test.expected, ledger.Dispose("BTC", test.quantity, test.proceeds, day3))
+		assertDisposals(t, test.name+" ledger", test.expected, ledger.Disposals)
+
+		holding, cost := ledger.Holding("BTC")
+		assertFloat(t, test.name+" holding", test.holding, holding)
+		assertFloat(t, test.name+" holding cost", test.cost, cost)
+	}
+}
+
+func TestAverage(t *testing.T) {
+	// 4 BTC at an average of 150 after the second lot
+	ledger := newTestLedger(Average, []acquisition{{1, 100, day1}, {3, 500, day2}})
+	ledger.Acquire("ETH", 1, 10, day2)
+
+	var quantity, proceeds, cost float64
+	for _, disposal := range ledger.Dispose("BTC", 2, 400, day3) {
+		quantity += disposal.Quantity
+		proceeds += disposal.Proceeds
+		cost += disposal.Cost
+	}
+	assertFloat(t, "quantity", 2, quantity)
+	assertFloat(t, "proceeds", 400, proceeds)
+	assertFloat(t, "cost", 300, cost)
+	assertFloat(t, "realized", 100, ledger.Realized("BTC"))
+
+	holding, holdingCost := ledger.Holding("BTC")
+	assertFloat(t, "holding", 2, holding)
+	assertFloat(t, "holding cost", 300, holdingCost)
+
+	// other assets are pooled separately
+	holding, holdingCost = ledger.Holding("ETH")
+	assertFloat(t, "other holding", 1, holding)
+	assertFloat(t, "other holding cost", 10, holdingCost)
+}
+
+func TestParseMethod(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected Method
+		valid    bool
+	}{
+		{"fifo", FIFO, true},
+		{"LIFO", LIFO, true},
+		{"Average", Average, true},
+		{"hifo", "", false},
+	}
+	for _, test := range tests {
+		method, err := ParseMethod(test.name)
+		if method != test.expected || (err == nil) != test.valid {
+			t.Errorf("%s: expected %q, got %q (%v)", test.name, test.expected, method, err)
+		}
+	}
+}
+
+func TestAverageKeepsLotDates(t *testing.T) {
+	ledger := newTestLedger(Average, []acquisition{{1, 100, day1}, {3, 500, day2}})
+
+	// every unit costs 150 but the holding period starts when its lot was bought
+	assertDisposals(t, "average", []Disposal{
+		{Asset: "BTC", Quantity: 1, Proceeds: 200, Cost: 150, Acquired: day1, Disposed: day3},
+		{Asset: "BTC", Quantity: 1, Proceeds: 200, Cost: 150, Acquired: day2, Disposed: day3},
+	}, ledger.Dispose("BTC", 2, 400, day3))
+}
@@ -0,0 +1,206 @@
+// Package indicators calculates technical indicators over price series. Every function returns a series the same
+// length as its input with NaN for the values before the indicator has enough data.
+package indicators
+
+import "math"
+
+// nanSeries returns a series of NaN values.
+func nanSeries(length int) []float64 {
+	series := make([]float64, length)
+	for index := range series {
+		series[index] = math.NaN()
+	}
+	return series
+}
+
+// Last returns the last value of the series or NaN if it is empty.
+func Last(series []float64) float64 {
+	if len(series) == 0 {
+		return math.NaN()
+	}
+	return series[len(series)-1]
+}
+
+// SMA is the simple moving average of the values over the period.
+func SMA(values []float64, period int) []float64 {
+	result := nanSeries(len(values))
+	if period <= 0 || len(values) < period {
+		return result
+	}
+
+	var sum float64
+	for index, value := range values {
+		sum += value
+		if index >= period {
+			sum -= values[index-period]
+		}
+		if index >= period-1 {
+			result[index] = sum / float64(period)
+		}
+	}
+	return result
+}
+
+// EMA is the exponential moving average of the values over the period. It is seeded with the SMA of the first period
+// values.
+func EMA(values []float64, period int) []float64 {
+	result := nanSeries(len(values))
+	if period <= 0 || len(values) < period {
+		return result
+	}
+
+	var sum float64
+	for _, value := range values[:period] {
+		sum += value
+	}
+	result[period-1] = sum / float64(period)
+
+	k := 2 / float64(period+1)
+	for index := period; index < len(values); index++ {
+		result[index] = values[index]*k + result[index-1]*(1-k)
+	}
+	return result
+}
+
+// wilder smooths the values with Wilder's moving average. It is seeded with the average of the first period values
+// starting at the offset.
+func wilder(values []float64, period, offset int) []float64 {
+	result := nanSeries(len(values))
+	if period <= 0 || len(values) < offset+period {
+		return result
+	}
+
+	var sum float64
+	for _, value := range values[offset : offset+period] {
+		sum += value
+	}
+	start := offset + period - 1
+	result[start] = sum / float64(period)
+
+	for index := start + 1; index < len(values); index++ {
+		result[index] = (result[index-1]*float64(period-1) + values[index]) / float64(period)
+	}
+	return result
+}
+
+// RSI is the relative strength index of the values over the period using Wilder's smoothing.
+func RSI(values []float64, period int) []float64 {
+	result := nanSeries(len(values))
+	if period <= 0 || len(values) <= period {
+		return result
+	}
+
+	gains := make([]float64, len(values))
+	losses := make([]float64, len(values))
+	for index := 1; index < len(values); index++ {
+		change := values[index] - values[index-1]
+		if change > 0 {
+			gains[index] = change
+		} else {
+			losses[index] = -change
+		}
+	}
+
+	// the first value has no change so smoothing starts at the second
+	avgGain := wilder(gains, period, 1)
+	avgLoss := wilder(losses, period, 1)
+	for index := period; index < len(values); index++ {
+		if avgLoss[index] == 0 {
+			result[index] = 100
+			continue
+		}
+		rs := avgGain[index] / avgLoss[index]
+		result[index] = 100 - 100/(1+rs)
+	}
+	return result
+}
+
+// MACD is the moving average convergence divergence. It returns the MACD line (fast EMA minus slow EMA), the signal
+// line (EMA of the MACD line) and the histogram (MACD minus signal).
+func MACD(values []float64, fast, slow, signal int) ([]float64, []float64, []float64) {
+	line := nanSeries(len(values))
+	signalLine := nanSeries(len(values))
+	histogram := nanSeries(len(values))
+	if fast <= 0 || slow <= fast || signal <= 0 || len(values) < slow {
+		return line, signalLine, histogram
+	}
+
+	fastEMA := EMA(values, fast)
+	slowEMA := EMA(values, slow)
+	for index := slow - 1; index < len(values); index++ {
+		line[index] = fastEMA[index] - slowEMA[index]
+	}
+
+	// the signal is calculated from the first MACD value
+	smoothed := EMA(line[slow-1:], signal)
+	for index, value := range smoothed {
+		signalLine[slow-1+index] = value
+		histogram[slow-1+index] = line[slow-1+index] - value
+	}
+	return line, signalLine, histogram
+}
+
+// Bollinger returns the upper, middle and lower Bollinger Bands. The middle band is the SMA over the period and the
+// outer bands are k population standard deviations away.
+func Bollinger(values []float64, period int, k float64) ([]float64, []float64, []float64) {
+	upper := nanSeries(len(values))
+	lower := nanSeries(len(values))
+	middle := SMA(values, period)
+	if period <= 0 || len(values) < period {
+		return upper, middle, lower
+	}
+
+	for index := period - 1; index < len(values); index++ {
+		var variance float64
+		for _, value := range values[index-period+1 : index+1] {
+			variance += (value - middle[index]) * (value - middle[index])
+		}
+		deviation := math.Sqrt(variance / float64(period))
+		upper[index] = middle[index] + k*deviation
+		lower[index] = middle[index] - k*deviation
+	}
+	return upper, middle, lower
+}
+
+// TrueRange is the greatest of the high minus the low and the distance of each from the previous close.
+func TrueRange(high, low, close []float64) []float64 {
+	result := nanSeries(len(close))
+	for index := range close {
+		result[index] = high[index] - low[index]
+		if index > 0 {
+			result[index] = math.Max(result[index], math.Abs(high[index]-close[index-1]))
+			result[index] = math.Max(result[index], math.Abs(low[index]-close[index-1]))
+		}
+	}
+	return result
+}
+
+// ATR is the average true range over the period using Wilder's smoothing.
+func ATR(high, low, close []float64, period int) []float64 {
+	if len(high) != len(close) || len(low) != len(close) {
+		return nanSeries(len(close))
+	}
+
+	// the first true range has no previous close so smoothing starts at the second
+	return wilder(TrueRange(high, low, close), period, 1)
+}
+
+// VWAP is the cumulative volume weighted average price using the typical price (high + low + close) / 3. The sum
+// starts at the first value so the series should be limited to the session being measured.
+func VWAP(high, low, close, volume []float64) []float64 {
+	result := nanSeries(len(close))
+	if len(high) != len(close) || len(low) != len(close) || len(volume) != len(close) {
+		return result
+	}
+
+	var priceVolume, totalVolume float64
+	for index := range close {
+		typical := (high[index] + low[index] + close[index]) / 3
+		priceVolume += typical * volume[index]
+		totalVolume += volume[index]
+		if totalVolume > 0 {
+			result[index] = priceVolume / totalVolume
+		}
+	}
+	return result
+}
@@ -0,0 +1,206 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+)
+
+var nan = math.NaN()
+
+// wilderCloses is the RSI example from Wilder's book as used by StockCharts. StockCharts rounds the averages in its
+// spreadsheet, so the published values differ from the exact ones in the second decimal.
+var wilderCloses = []float64{
+	44.34, 44.09, 44.15, 43.61, 44.33, 44.83, 45.10, 45.42, 45.84, 46.08, 45.89, 46.03, 45.61, 46.28, 46.28, 46.00,
+	46.03, 46.41, 46.22, 45.64, 46.21, 46.25, 45.71, 46.45, 45.78, 45.35, 44.03, 44.18, 44.22, 44.57, 43.42, 42.66,
+	43.13,
+}
+
+func assertSeries(t *testing.T, name string, expected, actual []float64, tolerance float64) {
+	t.Helper()
+	if len(expected) != len(actual) {
+		t.Fatalf("%s: expected %d values, got %d", name, len(expected), len(actual))
+	}
+	for index := range expected {
+		if math.IsNaN(expected[index]) {
+			if !math.IsNaN(actual[index]) {
+				t.Errorf("%s[%d]: expected NaN, got %f", name, index, actual[index])
+			}
+		} else if math.Abs(expected[index]-actual[index]) > tolerance {
+			t.Errorf("%s[%d]: expected %f, got %f", name, index, expected[index], actual[index])
+		}
+	}
+}
+
+func TestSMA(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []float64
+		period   int
+		expected []float64
+	}{
+		{"linear", []float64{1, 2, 3, 4, 5}, 3, []float64{nan, nan, 2, 3, 4}},
+		{"uneven", []float64{2, 4, 6, 8, 12}, 2, []float64{nan, 3, 5, 7, 10}},
+		{"period of one", []float64{5, 7}, 1, []float64{5, 7}},
+		{"too short", []float64{1, 2}, 3, []float64{nan, nan}},
+		{"zero period", []float64{1, 2}, 0, []float64{nan, nan}},
+	}
+	for _, test := range tests {
+		assertSeries(t, test.name, test.expected, SMA(test.values, test.period), 1e-9)
+	}
+}
+
+func TestEMA(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []float64
+		period   int
+		expected []float64
+	}{
+		// seeded with SMA 4 and smoothed with k = 2 / (3 + 1)
+		{"uneven", []float64{2, 4, 6, 8, 12}, 3, []float64{nan, nan, 4, 6, 9}},
+		// on a linear series the EMA lags by (period - 1) / 2
+		{"linear", []float64{1, 2, 3, 4, 5, 6}, 5, []float64{nan, nan, nan, nan, 3, 4}},
+		{"too short", []float64{1, 2}, 3, []float64{nan, nan}},
+	}
+	for _, test := range tests {
+		assertSeries(t, test.name, test.expected, EMA(test.values, test.period), 1e-9)
+	}
+}
+
+func TestRSI(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []float64
+		period   int
+		expected []float64
+	}{
+		{"wilder", wilderCloses, 14, append(nanSeries(14),
+			70.46, 66.25, 66.48, 69.35, 66.29, 57.92, 62.88, 63.21, 56.01, 62.34, 54.67, 50.39, 40.02, 41.49, 41.90,
+			45.50, 37.32, 33.09, 37.79,
+		)},
+		{"only gains", []float64{1, 2, 3, 4}, 2, []float64{nan, nan, 100, 100}},
+		{"only losses", []float64{4, 3, 2, 1}, 2, []float64{nan, nan, 0, 0}},
+		{"too short", []float64{1, 2}, 2, []float64{nan, nan}},
+	}
+	for _, test := range tests {
+		assertSeries(t, test.name, test.expected, RSI(test.values, test.period), 0.01)
+	}
+}
+
+func TestMACD(t *testing.T) {
+	tests := []struct {
+		name                        string
+		values                      []float64
+		fast, slow, signal          int
+		line, signalLine, histogram []float64
+	}{
+		// on a linear series the fast EMA lags by 0.5 and the slow EMA by 1
+		{
+			"linear", []float64{1, 2, 3, 4, 5, 6, 7, 8}, 2, 3, 2,
+			[]float64{nan, nan, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5},
+			[]float64{nan, nan, nan, 0.5, 0.5, 0.5, 0.5, 0.5},
+			[]float64{nan, nan, nan, 0, 0, 0, 0, 0},
+		},
+		// from the third value the fast EMA(2) is 5, 7, 31/3 and the slow EMA(3) is 4, 6, 9
+		{
+			"uneven", []float64{2, 4, 6, 8, 12}, 2, 3, 2,
+			[]float64{nan, nan, 1, 1, 4.0 / 3},
+			[]float64{nan, nan, nan, 1, 11.0 / 9},
+			[]float64{nan, nan, nan, 0, 1.0 / 9},
+		},
+		{
+			"slow not above fast", []float64{1, 2, 3}, 2, 2, 2,
+			[]float64{nan, nan, nan}, []float64{nan, nan, nan}, []float64{nan, nan, nan},
+		},
+	}
+	for _, test := range tests {
+		line, signal, histogram := MACD(test.values, test.fast, test.slow, test.signal)
+		assertSeries(t, test.name+" line", test.line, line, 1e-9)
+		assertSeries(t, test.name+" signal", test.signalLine, signal, 1e-9)
+		assertSeries(t, test.name+" histogram", test.histogram, histogram, 1e-9)
+	}
+}
+
+func TestBollinger(t *testing.T) {
+	tests := []struct {
+		name                 string
+		values               []float64
+		period               int
+		k                    float64
+		upper, middle, lower []float64
+	}{
+		// the population standard deviation of 1..5 is sqrt(2)
+		{
+			"linear", []float64{1, 2, 3, 4, 5}, 5, 2,
+			[]float64{nan, nan, nan, nan, 3 + 2*math.Sqrt2},
+			[]float64{nan, nan, nan, nan, 3},
+			[]float64{nan, nan, nan, nan, 3 - 2*math.Sqrt2},
+		},
+		{
+			"flat", []float64{4, 4, 4}, 2, 2,
+			[]float64{nan, 4, 4}, []float64{nan, 4, 4}, []float64{nan, 4, 4},
+		},
+		{
+			"too short", []float64{1}, 2, 2,
+			[]float64{nan}, []float64{nan}, []float64{nan},
+		},
+	}
+	for _, test := range tests {
+		upper, middle, lower := Bollinger(test.values, test.period, test.k)
+		assertSeries(t, test.name+" upper", test.upper, upper, 1e-9)
+		assertSeries(t, test.name+" middle", test.middle, middle, 1e-9)
+		assertSeries(t, test.name+" lower", test.lower, lower, 1e-9)
+	}
+}
+
+func TestATR(t *testing.T) {
+	tests := []struct {
+		name             string
+		high, low, close []float64
+		period           int
+		trueRange, atr   []float64
+	}{
+		// the second range comes from the high, the third from the gap down and the fourth from the gap up
+		{
+			"gaps", []float64{10, 12, 11, 15}, []float64{8, 9, 7, 12}, []float64{9, 11, 8, 14}, 2,
+			[]float64{2, 3, 4, 7},
+			[]float64{nan, nan, 3.5, 5.25},
+		},
+		{
+			"mismatched lengths", []float64{10, 12}, []float64{8}, []float64{9, 11}, 1,
+			[]float64{2, 3},
+			[]float64{nan, nan},
+		},
+	}
+	for _, test := range tests {
+		if len(test.high) == len(test.close) && len(test.low) == len(test.close) {
+			assertSeries(t, test.name+" true range", test.trueRange, TrueRange(test.high, test.low, test.close), 1e-9)
+		}
+		assertSeries(t, test.name+" atr", test.atr, ATR(test.high, test.low, test.close, test.period), 1e-9)
+	}
+}
+
+func TestVWAP(t *testing.T) {
+	tests := []struct {
+		name                     string
+		high, low, close, volume []float64
+		expected                 []float64
+	}{
+		// typical prices are 9 and 11
+		{"weighted", []float64{10, 12}, []float64{8, 10}, []float64{9, 11}, []float64{1, 3}, []float64{9, 10.5}},
+		{"no volume yet", []float64{10, 12}, []float64{8, 10}, []float64{9, 11}, []float64{0, 2}, []float64{nan, 11}},
+		{"mismatched lengths", []float64{10}, []float64{8}, []float64{9}, []float64{}, []float64{nan}},
+	}
+	for _, test := range tests {
+		assertSeries(t, test.name, test.expected, VWAP(test.high, test.low, test.close, test.volume), 1e-9)
+	}
+}
+
+func TestLast(t *testing.T) {
+	if value := Last([]float64{1, 2}); value != 2 {
+		t.Errorf("expected 2, got %f", value)
+	}
+	if value := Last(nil); !math.IsNaN(value) {
+		t.Errorf("expected NaN, got %f", value)
+	}
+}
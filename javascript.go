@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+	"github.com/dop251/goja"
+	"github.com/eliquious/console"
+	"github.com/gookit/color"
+)
+
+// newEvalCommand creates the JS interpreter with the given objects as globals. Go methods are called with a lower
+// case first letter and Go results use their JSON field names. A Go error is thrown as a JS exception.
+func newEvalCommand(objects map[string]interface{}) *console.Command {
+	vm := goja.New()
+	vm.SetFieldNameMapper(goja.TagFieldNameMapper("json", true))
+	for name, object := range objects {
+		vm.Set(name, object)
+	}
+
+	var file string
+	command := &console.Command{
+		Use:   "eval",
+		Short: "Launch JS interpreter",
+		Long: `Evaluates JS with the binance and shopify objects, or starts an interactive interpreter if no code is given.
+Variables are kept between evaluations. Type exit or press Ctrl-D to leave the interpreter.
+
+    binance.price(symbol)                    last price
+    binance.prices()                         last price of every symbol
+    binance.balances()                       non-zero balances (paper balances if binance.paper is set)
+    binance.klines(symbol, interval, limit)  latest klines (1d and 100 by default)
+    binance.depth(symbol, levels)            order book (10 levels by default)
+    binance.buy(symbol, qty, price)          limit buy order
+    binance.sell(symbol, qty, price)         limit sell order
+    binance.cancel(symbol, orderId)          cancel an order
+    shopify.revenue(cost, price, goal)       revenue projection
+
+Orders are placed and cancelled without confirmation, so they only go to the exchange if binance.script_orders is set
+to true. Otherwise set binance.paper to trade on paper.
+
+    eval "binance.klines('BTCUSDT', '1h', 24).map(k => k.close)"
+    eval -f analysis.js
+		`,
+		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
+			if cmd.Flags().Changed("file") {
+				source, err := ioutil.ReadFile(file)
+				if err != nil {
+					return err
+				}
+				return evaluate(vm, file, string(source))
+			} else if len(args) > 0 {
+				return evaluate(vm, "eval", strings.Join(args, " "))
+			}
+
+			runEvalPrompt(env, vm)
+			return nil
+		},
+		IsBuiltIn:       true,
+		ShouldPropagate: true,
+	}
+	command.Flags().StringVarP(&file, "file", "f", "", "Run a JS file")
+	return command
+}
+
+// evaluate runs the code and prints the result.
+func evaluate(vm *goja.Runtime, name, code string) error {
+	value, err := vm.RunScript(name, code)
+	if err != nil {
+		var exception *goja.Exception
+		if errors.As(err, &exception) {
+			return errors.New(exception.Value().String())
+		}
+		return err
+	}
+
+	if text := formatValue(value); text != "" {
+		fmt.Println(text)
+	}
+	return nil
+}
+
+// formatValue prints objects and arrays as JSON. Undefined is not printed.
+func formatValue(value goja.Value) string {
+	if value == nil || goja.IsUndefined(value) {
+		return ""
+	} else if _, ok := goja.AssertFunction(value); ok {
+		return value.String()
+	}
+
+	switch exported := value.Export().(type) {
+	case string:
+		return exported
+	case nil, bool, int64, float64:
+		return value.String()
+	default:
+		data, err := json.MarshalIndent(exported, "", "  ")
+		if err != nil {
+			return value.String()
+		}
+		return string(data)
+	}
+}
+
+// runEvalPrompt runs the interactive interpreter until exit or Ctrl-D.
+func runEvalPrompt(env *console.Environment, vm *goja.Runtime) {
+	prefix := func() (string, bool) {
+		scopes := []string{}
+		for _, scope := range env.ScopeStack {
+			scopes = append(scopes, scope.Name)
+		}
+		scopes = append(scopes, "eval")
+		return strings.Join(scopes, ":") + env.Prefix, true
+	}
+
+	isExit := func(line string, breakline bool) bool {
+		line = strings.TrimSpace(line)
+		return breakline && (line == "exit" || line == "pop")
+	}
+
+	executor := func(line string) {
+		line = strings.TrimSpace(line)
+		if line == "" || isExit(line, true) {
+			return
+		}
+		if err := evaluate(vm, "eval", line); err != nil {
+			color.Error.Println(err)
+		}
+	}
+
+	completer := func(prompt.Document) []prompt.Suggest { return []prompt.Suggest{} }
+	prompt.New(executor, completer,
+		prompt.OptionTitle("goja"),
+		prompt.OptionLivePrefix(prefix),
+		prompt.OptionSetExitCheckerOnInput(isExit),
+		prompt.OptionSwitchKeyBindMode(prompt.EmacsKeyBind),
+	).Run()
+}
@@ -1,34 +1,76 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"os"
+
 	"github.com/eliquious/console"
-	"github.com/eliquious/console/ext/js"
 	"github.com/eliquious/mercator/binance"
+	"github.com/eliquious/mercator/output"
 	"github.com/eliquious/mercator/shopify"
 	"github.com/gookit/color"
 )
 
 func main() {
+	script := flag.String("f", "", "Run the commands in a script file and exit")
+	format := flag.String("output", "", "Output format: table, json, csv or yaml")
+	yes := flag.Bool("yes", false, "Answer yes to confirmations, such as placing orders (withdrawals always ask)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage:\n  mercator [flags]                   start the console\n  mercator [flags] SCOPE COMMAND...  run a command and exit\n  mercator [flags] -f FILE           run a script and exit\n\nFlags:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
 	c := console.New("mercator", console.WithTitleScreen(printASCII))
 
+	// load config file
+	if err := loadConfig(c.Environment().Configuration); err != nil {
+		color.Error.Println(err)
+		os.Exit(1)
+	}
+
+	// results print as tables unless the output setting is changed
+	c.Environment().Configuration.SetDefault(output.Key, string(output.Table))
+	if *format != "" {
+		if _, err := output.ParseFormat(*format); err != nil {
+			color.Error.Println(err)
+			os.Exit(2)
+		}
+		c.Environment().Configuration.Set(output.Key, *format)
+	}
+	if *yes {
+		c.Environment().Configuration.Set(binance.AssumeYesKey, true)
+	}
+
 	// add shopify scope
-	shopify, err := shopify.NewShopifyScope()
+	shopify, shopifyAPI, err := shopify.NewShopifyScope(c.Environment())
 	if err != nil {
 		color.Error.Println(err)
-		return
+		os.Exit(1)
 	}
 	c.AddScope(shopify)
 
 	// add binance scope
-	binance, err := binance.NewBinanceExchangeScope()
+	binance, binanceAPI, err := binance.NewBinanceExchangeScope(c.Environment())
 	if err != nil {
 		color.Error.Println(err)
-		return
+		os.Exit(1)
 	}
 	c.AddScope(binance)
 
 	// add global JS interpreter
-	c.AddCommand(js.EvalCommand())
+	c.AddCommand(newEvalCommand(map[string]interface{}{
+		"binance": binanceAPI,
+		"shopify": shopifyAPI,
+	}))
+
+	// run a script or a single command without the prompt
+	if *script != "" {
+		os.Exit(runScript(c.Environment(), *script))
+	} else if flag.NArg() > 0 {
+		os.Exit(runCommand(c.Environment(), flag.Args()))
+	}
 
 	// start console
 	c.Run()
@@ -0,0 +1,124 @@
+// Package output renders command results as a table, JSON, CSV or YAML. The table format is the human readable
+// output of the console and the other formats are meant for scripts and dashboards.
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/eliquious/console"
+	"gopkg.in/yaml.v2"
+)
+
+// Key is the configuration key of the default output format. It can be changed with `set output json`.
+const Key = "output"
+
+// Format is an output format.
+type Format string
+
+// Supported output formats.
+const (
+	Table Format = "table"
+	JSON  Format = "json"
+	CSV   Format = "csv"
+	YAML  Format = "yaml"
+)
+
+// Formats lists the supported output formats.
+var Formats = []string{string(Table), string(JSON), string(CSV), string(YAML)}
+
+// Result is the typed result of a command. JSON and YAML encode the result itself, CSV writes the records and the
+// table format prints the result for the console.
+type Result interface {
+	// Print writes the human readable output.
+	Print(w io.Writer)
+
+	// Records returns the rows of the result for CSV, starting with the header.
+	Records() [][]string
+}
+
+// ParseFormat parses an output format name.
+func ParseFormat(name string) (Format, error) {
+	for _, format := range Formats {
+		if strings.EqualFold(name, format) {
+			return Format(format), nil
+		}
+	}
+	return "", fmt.Errorf("unknown output format: %s (expected table, json, csv or yaml)", name)
+}
+
+// AddFlag adds the --output flag to a command.
+func AddFlag(cmd *console.Command) {
+	cmd.Flags().String("output", "", "Output format: table, json, csv or yaml (defaults to the output setting)")
+	cmd.Flags().Lookup("output").Annotations = map[string][]string{console.Suggestions: Formats}
+}
+
+// GetFormat returns the format given with the --output flag of the command or the output setting.
+func GetFormat(env *console.Environment, cmd *console.Command) (Format, error) {
+	if cmd.Flags().Changed("output") {
+		name, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return "", err
+		}
+		return ParseFormat(name)
+	}
+
+	name := env.Configuration.GetString(Key)
+	if name == "" {
+		return Table, nil
+	}
+	return ParseFormat(name)
+}
+
+// IsTable returns true if the command prints in the table format. Commands use it to skip output that only makes
+// sense on a terminal.
+func IsTable(env *console.Environment, cmd *console.Command) bool {
+	format, err := GetFormat(env, cmd)
+	return err != nil || format == Table
+}
+
+// Render writes the result to stdout in the format of the command.
+func Render(env *console.Environment, cmd *console.Command, result Result) error {
+	format, err := GetFormat(env, cmd)
+	if err != nil {
+		return err
+	}
+	return Write(os.Stdout, format, result)
+}
+
+// Write writes the result in a format.
+func Write(w io.Writer, format Format, result Result) error {
+	switch format {
+	case Table:
+		result.Print(w)
+		return nil
+	case JSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	case YAML:
+		data, err := yaml.Marshal(result)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	case CSV:
+		writer := csv.NewWriter(w)
+		if err := writer.WriteAll(result.Records()); err != nil {
+			return err
+		}
+		return writer.Error()
+	}
+	return fmt.Errorf("unknown output format: %s", format)
+}
+
+// FormatFloat formats a number for CSV without an exponent or trailing zeros.
+func FormatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
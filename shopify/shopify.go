@@ -2,34 +2,26 @@ package shopify
 
 import (
 	"fmt"
+	"io"
 	"math"
 
 	"github.com/eliquious/console"
+	"github.com/eliquious/mercator/output"
+	"github.com/gookit/color"
+	"github.com/spf13/viper"
 )
 
-// NewShopifyScope creates a new Shopify scope for the CLI.
-func NewShopifyScope() (*console.Scope, error) {
+// NewShopifyScope creates a new Shopify scope for the CLI and the shopify object of the JS interpreter.
+func NewShopifyScope(env *console.Environment) (*console.Scope, *ScriptAPI, error) {
 	shopify := console.NewScope("shopify", "Utilities for managing shopify account")
-	shopify.InitializeFunc = func(env *console.Environment) {
-		env.Configuration.SetDefault("shopify.cpm", 6.2)
-		env.Configuration.SetDefault("shopify.ctr", 0.0259)
-		env.Configuration.SetDefault("shopify.conv", 0.03)
-	}
+
+	// the defaults are set up front since scripts use the configuration without entering the scope
+	setConfigDefaults(env.Configuration)
 
 	revenueCommand := &console.Command{
 		Use:   "revenue",
 		Short: "Calculates estimated revenue based on projections",
 		Run: func(env *console.Environment, cmd *console.Command, args []string) error {
-			costPerMille := env.Configuration.GetFloat64("shopify.cpm")
-			console.PrintInfo("CPM", "$%.2f", costPerMille)
-
-			clickThroughRate := env.Configuration.GetFloat64("shopify.ctr")
-			console.PrintInfo("CTR", "%.2f%%", clickThroughRate*100)
-
-			conversionRate := env.Configuration.GetFloat64("shopify.conv")
-			console.PrintInfo("Conversion Rate", "%.2f%%", conversionRate*100)
-			fmt.Println()
-
 			productCost, err := cmd.Flags().GetFloat64("cost")
 			if err != nil {
 				return err
@@ -45,50 +37,159 @@ func NewShopifyScope() (*console.Scope, error) {
 				return err
 			}
 
-			revenuePerSale := productTotal - productCost
-			console.PrintInfo("Gross Earnings Goal", "$%0.2f", earningsGoal)
-			console.PrintInfo("Product Total", "$%0.2f", productTotal)
-			console.PrintInfo("Product Cost", "$%0.2f", productCost)
-			console.PrintInfo("Revenue per Sale", "$%0.2f", revenuePerSale)
-			fmt.Println()
-
-			// Sales per month
-			var sales = math.Floor(earningsGoal/productTotal + 1)
-
-			// Required Visitors
-			var visitors = sales / conversionRate
-			var adImpressions = visitors / clickThroughRate
-
-			// Product Cost + Earnings
-			var gross = sales * productTotal
-			var productExpenses = sales * productCost
-			var marketingBudget = adImpressions / 1000.0 * costPerMille
-			var revenue = gross - productExpenses - marketingBudget
-			var costPerPurchase = marketingBudget / float64(sales)
-
-			console.PrintInfo("Required Gross Sales", "%.0f", sales)
-			console.PrintInfo("Required Visitors", "%.0f", visitors)
-			console.PrintInfo("Required Ad Impressions", "%.0f", adImpressions)
-			fmt.Println()
-
-			console.PrintInfo("Gross", "$%.2f", gross)
-			console.PrintInfo("Total Product Cost", "$%.2f", productExpenses)
-			console.PrintInfo("Required Marketing Budget", "$%.2f", marketingBudget)
-			console.PrintInfo("Net Revenue", "$%.2f", revenue)
-			fmt.Println()
-
-			console.PrintInfo("Profit/Marketing Ratio", "%.4f", revenue/marketingBudget)
-			console.PrintInfo("Profit/Expenses Ratio", "%.4f", revenue/(marketingBudget+productExpenses))
-			console.PrintInfo("Marketing Cost per Visitor", "$%.2f", marketingBudget/visitors)
-			console.PrintInfo("Marketing Cost per Purchase", "$%.2f", costPerPurchase)
-			console.PrintInfo("Profit per Sale", "$%.2f", revenuePerSale-costPerPurchase)
-			fmt.Println()
-			return nil
+			result := newRevenueResult(
+				env.Configuration.GetFloat64("shopify.cpm"),
+				env.Configuration.GetFloat64("shopify.ctr"),
+				env.Configuration.GetFloat64("shopify.conv"),
+				productCost,
+				productTotal,
+				earningsGoal,
+			)
+			return output.Render(env, cmd, result)
 		},
 	}
 	revenueCommand.Flags().Float64("cost", 1, "Product cost")
 	revenueCommand.Flags().Float64("price", 1, "Product sale price")
 	revenueCommand.Flags().Float64("goal", 1000, "Sales goal")
+	output.AddFlag(revenueCommand)
 	shopify.AddCommand(revenueCommand)
-	return shopify, nil
+	return shopify, &ScriptAPI{conf: env.Configuration}, nil
+}
+
+// setConfigDefaults sets the defaults of the shopify configuration.
+func setConfigDefaults(conf *viper.Viper) {
+	conf.SetDefault("shopify.cpm", 6.2)
+	conf.SetDefault("shopify.ctr", 0.0259)
+	conf.SetDefault("shopify.conv", 0.03)
+}
+
+// ScriptAPI is the shopify object of the JS interpreter.
+type ScriptAPI struct {
+	conf *viper.Viper
+}
+
+// Revenue projects the revenue of a product using the shopify.cpm, shopify.ctr and shopify.conv settings.
+func (api *ScriptAPI) Revenue(cost, price, goal float64) *revenueResult {
+	return newRevenueResult(
+		api.conf.GetFloat64("shopify.cpm"),
+		api.conf.GetFloat64("shopify.ctr"),
+		api.conf.GetFloat64("shopify.conv"),
+		cost,
+		price,
+		goal,
+	)
+}
+
+// revenueResult is the revenue projection for a product.
+type revenueResult struct {
+	CostPerMille     float64 `json:"cpm" yaml:"cpm"`
+	ClickThroughRate float64 `json:"ctr" yaml:"ctr"`
+	ConversionRate   float64 `json:"conversionRate" yaml:"conversionRate"`
+
+	EarningsGoal    float64 `json:"earningsGoal" yaml:"earningsGoal"`
+	ProductTotal    float64 `json:"productTotal" yaml:"productTotal"`
+	ProductCost     float64 `json:"productCost" yaml:"productCost"`
+	RevenuePerSale  float64 `json:"revenuePerSale" yaml:"revenuePerSale"`
+	Sales           float64 `json:"sales" yaml:"sales"`
+	Visitors        float64 `json:"visitors" yaml:"visitors"`
+	AdImpressions   float64 `json:"adImpressions" yaml:"adImpressions"`
+	Gross           float64 `json:"gross" yaml:"gross"`
+	ProductExpenses float64 `json:"productExpenses" yaml:"productExpenses"`
+	MarketingBudget float64 `json:"marketingBudget" yaml:"marketingBudget"`
+	Revenue         float64 `json:"revenue" yaml:"revenue"`
+
+	ProfitMarketingRatio float64 `json:"profitMarketingRatio" yaml:"profitMarketingRatio"`
+	ProfitExpensesRatio  float64 `json:"profitExpensesRatio" yaml:"profitExpensesRatio"`
+	CostPerVisitor       float64 `json:"costPerVisitor" yaml:"costPerVisitor"`
+	CostPerPurchase      float64 `json:"costPerPurchase" yaml:"costPerPurchase"`
+	ProfitPerSale        float64 `json:"profitPerSale" yaml:"profitPerSale"`
+}
+
+func newRevenueResult(costPerMille, clickThroughRate, conversionRate, productCost, productTotal, earningsGoal float64) *revenueResult {
+	r := &revenueResult{
+		CostPerMille:     costPerMille,
+		ClickThroughRate: clickThroughRate,
+		ConversionRate:   conversionRate,
+		EarningsGoal:     earningsGoal,
+		ProductTotal:     productTotal,
+		ProductCost:      productCost,
+		RevenuePerSale:   productTotal - productCost,
+	}
+
+	// Sales per month
+	r.Sales = math.Floor(earningsGoal/productTotal + 1)
+
+	// Required Visitors
+	r.Visitors = r.Sales / conversionRate
+	r.AdImpressions = r.Visitors / clickThroughRate
+
+	// Product Cost + Earnings
+	r.Gross = r.Sales * productTotal
+	r.ProductExpenses = r.Sales * productCost
+	r.MarketingBudget = r.AdImpressions / 1000.0 * costPerMille
+	r.Revenue = r.Gross - r.ProductExpenses - r.MarketingBudget
+	r.CostPerPurchase = r.MarketingBudget / r.Sales
+
+	r.ProfitMarketingRatio = r.Revenue / r.MarketingBudget
+	r.ProfitExpensesRatio = r.Revenue / (r.MarketingBudget + r.ProductExpenses)
+	r.CostPerVisitor = r.MarketingBudget / r.Visitors
+	r.ProfitPerSale = r.RevenuePerSale - r.CostPerPurchase
+	return r
+}
+
+func (r *revenueResult) Print(w io.Writer) {
+	printInfo(w, "CPM", "$%.2f", r.CostPerMille)
+	printInfo(w, "CTR", "%.2f%%", r.ClickThroughRate*100)
+	printInfo(w, "Conversion Rate", "%.2f%%", r.ConversionRate*100)
+	fmt.Fprintln(w)
+
+	printInfo(w, "Gross Earnings Goal", "$%0.2f", r.EarningsGoal)
+	printInfo(w, "Product Total", "$%0.2f", r.ProductTotal)
+	printInfo(w, "Product Cost", "$%0.2f", r.ProductCost)
+	printInfo(w, "Revenue per Sale", "$%0.2f", r.RevenuePerSale)
+	fmt.Fprintln(w)
+
+	printInfo(w, "Required Gross Sales", "%.0f", r.Sales)
+	printInfo(w, "Required Visitors", "%.0f", r.Visitors)
+	printInfo(w, "Required Ad Impressions", "%.0f", r.AdImpressions)
+	fmt.Fprintln(w)
+
+	printInfo(w, "Gross", "$%.2f", r.Gross)
+	printInfo(w, "Total Product Cost", "$%.2f", r.ProductExpenses)
+	printInfo(w, "Required Marketing Budget", "$%.2f", r.MarketingBudget)
+	printInfo(w, "Net Revenue", "$%.2f", r.Revenue)
+	fmt.Fprintln(w)
+
+	printInfo(w, "Profit/Marketing Ratio", "%.4f", r.ProfitMarketingRatio)
+	printInfo(w, "Profit/Expenses Ratio", "%.4f", r.ProfitExpensesRatio)
+	printInfo(w, "Marketing Cost per Visitor", "$%.2f", r.CostPerVisitor)
+	printInfo(w, "Marketing Cost per Purchase", "$%.2f", r.CostPerPurchase)
+	printInfo(w, "Profit per Sale", "$%.2f", r.ProfitPerSale)
+	fmt.Fprintln(w)
+}
+
+func (r *revenueResult) Records() [][]string {
+	values := []float64{
+		r.CostPerMille, r.ClickThroughRate, r.ConversionRate, r.EarningsGoal, r.ProductTotal, r.ProductCost,
+		r.RevenuePerSale, r.Sales, r.Visitors, r.AdImpressions, r.Gross, r.ProductExpenses, r.MarketingBudget,
+		r.Revenue, r.ProfitMarketingRatio, r.ProfitExpensesRatio, r.CostPerVisitor, r.CostPerPurchase, r.ProfitPerSale,
+	}
+	record := make([]string, 0, len(values))
+	for _, value := range values {
+		record = append(record, output.FormatFloat(value))
+	}
+
+	return [][]string{
+		{
+			"cpm", "ctr", "conversion_rate", "earnings_goal", "product_total", "product_cost", "revenue_per_sale",
+			"sales", "visitors", "ad_impressions", "gross", "product_expenses", "marketing_budget", "revenue",
+			"profit_marketing_ratio", "profit_expenses_ratio", "cost_per_visitor", "cost_per_purchase", "profit_per_sale",
+		},
+		record,
+	}
+}
+
+// printInfo prints a labeled value like console.PrintInfo to a writer.
+func printInfo(w io.Writer, label string, format string, value ...interface{}) {
+	fmt.Fprintf(w, "%s: %s\n", color.LightGreen.Render(label), fmt.Sprintf(format, value...))
 }